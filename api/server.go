@@ -6,7 +6,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/firecracker-microvm/firecracker-go-sdk"
 	"github.com/sirupsen/logrus"
 
 	"google.golang.org/grpc/keepalive"
@@ -37,7 +36,14 @@ func init() {
 }
 
 // Start starts catapult node server
-func Start(port int) {
+func Start(port int, cfg service.Config) {
+	if cfg.Network.Bridge.Bootstrap {
+		log.Info("Setting up the host network...")
+		if err := service.InitNetwork(log, cfg.Network); err != nil {
+			log.Fatalf("failed to set up the host network: %v", err)
+		}
+	}
+
 	log.Infof("Starting server on port %d...", port)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
@@ -50,7 +56,7 @@ func Start(port int) {
 		}),
 	)
 
-	nodeService := service.NewNodeService(log, make(map[string]*firecracker.Machine))
+	nodeService := service.NewNodeService(log, cfg)
 	node.RegisterNodeServer(server, nodeService)
 	if err := server.Serve(lis); err != nil {
 		log.Error(err)
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/PUMATeam/catapult-node/service"
+)
+
+// networkCmd groups the host network setup commands
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Manage the host network VMs are connected to",
+}
+
+var networkInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the bridge and enable forwarding and NAT for VMs",
+	Run: func(cmd *cobra.Command, args []string) {
+		runNetworkCommand(service.InitNetwork)
+	},
+}
+
+var networkTeardownCmd = &cobra.Command{
+	Use:   "teardown",
+	Short: "Remove the bridge and NAT rules created by network init",
+	Run: func(cmd *cobra.Command, args []string) {
+		runNetworkCommand(service.TeardownNetwork)
+	},
+}
+
+func runNetworkCommand(f func(*logrus.Logger, service.NetworkConfig) error) {
+	var cfg service.Config
+	err := viper.Unmarshal(&cfg)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Println("Invalid config file:", err)
+		os.Exit(1)
+	}
+
+	if err = f(logrus.New(), cfg.Network); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	networkCmd.AddCommand(networkInitCmd)
+	networkCmd.AddCommand(networkTeardownCmd)
+	rootCmd.AddCommand(networkCmd)
+}
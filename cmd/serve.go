@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/PUMATeam/catapult-node/api"
+	"github.com/PUMATeam/catapult-node/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var port int
@@ -12,7 +17,17 @@ var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start catapult node server",
 	Run: func(cmd *cobra.Command, args []string) {
-		api.Start(port)
+		var cfg service.Config
+		err := viper.Unmarshal(&cfg)
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err != nil {
+			fmt.Println("Invalid config file:", err)
+			os.Exit(1)
+		}
+
+		api.Start(port, cfg)
 	},
 }
 
@@ -25,6 +25,68 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type RestartPolicy int32
+
+const (
+	RestartPolicy_NEVER      RestartPolicy = 0
+	RestartPolicy_ON_FAILURE RestartPolicy = 1
+	RestartPolicy_ALWAYS     RestartPolicy = 2
+)
+
+var RestartPolicy_name = map[int32]string{
+	0: "NEVER",
+	1: "ON_FAILURE",
+	2: "ALWAYS",
+}
+
+var RestartPolicy_value = map[string]int32{
+	"NEVER":      0,
+	"ON_FAILURE": 1,
+	"ALWAYS":     2,
+}
+
+func (x RestartPolicy) String() string {
+	return proto.EnumName(RestartPolicy_name, int32(x))
+}
+
+func (RestartPolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{0}
+}
+
+type ProbeType int32
+
+const (
+	ProbeType_NONE   ProbeType = 0
+	ProbeType_TCP    ProbeType = 1
+	ProbeType_ICMP   ProbeType = 2
+	ProbeType_HTTP   ProbeType = 3
+	ProbeType_SERIAL ProbeType = 4
+)
+
+var ProbeType_name = map[int32]string{
+	0: "NONE",
+	1: "TCP",
+	2: "ICMP",
+	3: "HTTP",
+	4: "SERIAL",
+}
+
+var ProbeType_value = map[string]int32{
+	"NONE":   0,
+	"TCP":    1,
+	"ICMP":   2,
+	"HTTP":   3,
+	"SERIAL": 4,
+}
+
+func (x ProbeType) String() string {
+	return proto.EnumName(ProbeType_name, int32(x))
+}
+
+func (ProbeType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{1}
+}
+
 type Status int32
 
 const (
@@ -47,7 +109,136 @@ func (x Status) String() string {
 }
 
 func (Status) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{0}
+	return fileDescriptor_0c843d59d2d938e7, []int{2}
+}
+
+type VmState int32
+
+const (
+	VmState_UNKNOWN  VmState = 0
+	VmState_RUNNING  VmState = 1
+	VmState_PAUSED   VmState = 2
+	VmState_STOPPED  VmState = 3
+	VmState_STARTING VmState = 4
+	VmState_CRASHED  VmState = 5
+)
+
+var VmState_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "RUNNING",
+	2: "PAUSED",
+	3: "STOPPED",
+	4: "STARTING",
+	5: "CRASHED",
+}
+
+var VmState_value = map[string]int32{
+	"UNKNOWN":  0,
+	"RUNNING":  1,
+	"PAUSED":   2,
+	"STOPPED":  3,
+	"STARTING": 4,
+	"CRASHED":  5,
+}
+
+func (x VmState) String() string {
+	return proto.EnumName(VmState_name, int32(x))
+}
+
+func (VmState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{3}
+}
+
+type OperationState int32
+
+const (
+	OperationState_PENDING     OperationState = 0
+	OperationState_IN_PROGRESS OperationState = 1
+	OperationState_DONE        OperationState = 2
+	OperationState_ERROR       OperationState = 3
+	OperationState_CANCELLED   OperationState = 4
+)
+
+var OperationState_name = map[int32]string{
+	0: "PENDING",
+	1: "IN_PROGRESS",
+	2: "DONE",
+	3: "ERROR",
+	4: "CANCELLED",
+}
+
+var OperationState_value = map[string]int32{
+	"PENDING":     0,
+	"IN_PROGRESS": 1,
+	"DONE":        2,
+	"ERROR":       3,
+	"CANCELLED":   4,
+}
+
+func (x OperationState) String() string {
+	return proto.EnumName(OperationState_name, int32(x))
+}
+
+func (OperationState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{4}
+}
+
+type EraseMode int32
+
+const (
+	EraseMode_KEEP_DATA EraseMode = 0
+	// discards all blocks, thin backends release the space
+	EraseMode_DISCARD EraseMode = 1
+	// overwrites the whole volume with zeros
+	EraseMode_ZERO_FILL EraseMode = 2
+)
+
+var EraseMode_name = map[int32]string{
+	0: "KEEP_DATA",
+	1: "DISCARD",
+	2: "ZERO_FILL",
+}
+
+var EraseMode_value = map[string]int32{
+	"KEEP_DATA": 0,
+	"DISCARD":   1,
+	"ZERO_FILL": 2,
+}
+
+func (x EraseMode) String() string {
+	return proto.EnumName(EraseMode_name, int32(x))
+}
+
+func (EraseMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{5}
+}
+
+type ConnectMode int32
+
+const (
+	ConnectMode_FORMAT_AND_POPULATE ConnectMode = 0
+	ConnectMode_ATTACH_ONLY         ConnectMode = 1
+	ConnectMode_FORMAT_EMPTY        ConnectMode = 2
+)
+
+var ConnectMode_name = map[int32]string{
+	0: "FORMAT_AND_POPULATE",
+	1: "ATTACH_ONLY",
+	2: "FORMAT_EMPTY",
+}
+
+var ConnectMode_value = map[string]int32{
+	"FORMAT_AND_POPULATE": 0,
+	"ATTACH_ONLY":         1,
+	"FORMAT_EMPTY":        2,
+}
+
+func (x ConnectMode) String() string {
+	return proto.EnumName(ConnectMode_name, int32(x))
+}
+
+func (ConnectMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{6}
 }
 
 type UUID struct {
@@ -90,15 +281,29 @@ func (m *UUID) GetValue() string {
 }
 
 type VmConfig struct {
-	VmID                 *UUID    `protobuf:"bytes,1,opt,name=vmID,proto3" json:"vmID,omitempty"`
-	Memory               int64    `protobuf:"varint,2,opt,name=memory,proto3" json:"memory,omitempty"`
-	Vcpus                int64    `protobuf:"varint,3,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
-	KernelImage          string   `protobuf:"bytes,4,opt,name=kernelImage,proto3" json:"kernelImage,omitempty"`
-	RootFileSystem       string   `protobuf:"bytes,5,opt,name=rootFileSystem,proto3" json:"rootFileSystem,omitempty"`
-	Address              string   `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	VmID           *UUID           `protobuf:"bytes,1,opt,name=vmID,proto3" json:"vmID,omitempty"`
+	Memory         int64           `protobuf:"varint,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Vcpus          int64           `protobuf:"varint,3,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
+	KernelImage    string          `protobuf:"bytes,4,opt,name=kernelImage,proto3" json:"kernelImage,omitempty"`
+	RootFileSystem string          `protobuf:"bytes,5,opt,name=rootFileSystem,proto3" json:"rootFileSystem,omitempty"`
+	Address        string          `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`
+	Balloon        *BalloonConfig  `protobuf:"bytes,7,opt,name=balloon,proto3" json:"balloon,omitempty"`
+	EnableVsock    bool            `protobuf:"varint,8,opt,name=enableVsock,proto3" json:"enableVsock,omitempty"`
+	VsockCID       uint32          `protobuf:"varint,9,opt,name=vsockCID,proto3" json:"vsockCID,omitempty"`
+	ReadinessProbe *ReadinessProbe `protobuf:"bytes,10,opt,name=readinessProbe,proto3" json:"readinessProbe,omitempty"`
+	Restart        *RestartConfig  `protobuf:"bytes,11,opt,name=restart,proto3" json:"restart,omitempty"`
+	// boots the VM from a writable overlay of a base image, rootFileSystem
+	// is set to the overlay
+	RootfsOverlay *RootfsOverlay `protobuf:"bytes,12,opt,name=rootfsOverlay,proto3" json:"rootfsOverlay,omitempty"`
+	// network driver connecting the VM: bridge, routed or cni, the node's
+	// default if empty
+	Network string `protobuf:"bytes,13,opt,name=network,proto3" json:"network,omitempty"`
+	// only lets the traffic its rules allow reach or leave the VM,
+	// unrestricted if unset. Not supported by the cni network driver
+	Firewall             *Firewall `protobuf:"bytes,14,opt,name=firewall,proto3" json:"firewall,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *VmConfig) Reset()         { *m = VmConfig{} }
@@ -168,570 +373,2994 @@ func (m *VmConfig) GetAddress() string {
 	return ""
 }
 
-type Response struct {
-	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *VmConfig) GetBalloon() *BalloonConfig {
+	if m != nil {
+		return m.Balloon
+	}
+	return nil
 }
 
-func (m *Response) Reset()         { *m = Response{} }
-func (m *Response) String() string { return proto.CompactTextString(m) }
-func (*Response) ProtoMessage()    {}
-func (*Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{2}
+func (m *VmConfig) GetEnableVsock() bool {
+	if m != nil {
+		return m.EnableVsock
+	}
+	return false
 }
 
-func (m *Response) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Response.Unmarshal(m, b)
-}
-func (m *Response) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Response.Marshal(b, m, deterministic)
+func (m *VmConfig) GetVsockCID() uint32 {
+	if m != nil {
+		return m.VsockCID
+	}
+	return 0
 }
-func (m *Response) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Response.Merge(m, src)
+
+func (m *VmConfig) GetReadinessProbe() *ReadinessProbe {
+	if m != nil {
+		return m.ReadinessProbe
+	}
+	return nil
 }
-func (m *Response) XXX_Size() int {
-	return xxx_messageInfo_Response.Size(m)
+
+func (m *VmConfig) GetRestart() *RestartConfig {
+	if m != nil {
+		return m.Restart
+	}
+	return nil
 }
-func (m *Response) XXX_DiscardUnknown() {
-	xxx_messageInfo_Response.DiscardUnknown(m)
+
+func (m *VmConfig) GetRootfsOverlay() *RootfsOverlay {
+	if m != nil {
+		return m.RootfsOverlay
+	}
+	return nil
 }
 
-var xxx_messageInfo_Response proto.InternalMessageInfo
+func (m *VmConfig) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
 
-func (m *Response) GetStatus() Status {
+func (m *VmConfig) GetFirewall() *Firewall {
 	if m != nil {
-		return m.Status
+		return m.Firewall
 	}
-	return Status_SUCCESS
+	return nil
 }
 
-type VmResponse struct {
-	Status               Status    `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
-	Config               *VmConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+// Firewall of a VM, traffic not allowed by any rule is dropped. Replies to
+// allowed traffic are always let through, and so is ARP on a bridge. The
+// firewall of a bridged VM needs the nf_conntrack_bridge module of Linux 5.3
+// or later
+type Firewall struct {
+	Ingress              []*FirewallRule `protobuf:"bytes,1,rep,name=ingress,proto3" json:"ingress,omitempty"`
+	Egress               []*FirewallRule `protobuf:"bytes,2,rep,name=egress,proto3" json:"egress,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
-func (m *VmResponse) Reset()         { *m = VmResponse{} }
-func (m *VmResponse) String() string { return proto.CompactTextString(m) }
-func (*VmResponse) ProtoMessage()    {}
-func (*VmResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{3}
+func (m *Firewall) Reset()         { *m = Firewall{} }
+func (m *Firewall) String() string { return proto.CompactTextString(m) }
+func (*Firewall) ProtoMessage()    {}
+func (*Firewall) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{2}
 }
 
-func (m *VmResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_VmResponse.Unmarshal(m, b)
+func (m *Firewall) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Firewall.Unmarshal(m, b)
 }
-func (m *VmResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_VmResponse.Marshal(b, m, deterministic)
+func (m *Firewall) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Firewall.Marshal(b, m, deterministic)
 }
-func (m *VmResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_VmResponse.Merge(m, src)
+func (m *Firewall) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Firewall.Merge(m, src)
 }
-func (m *VmResponse) XXX_Size() int {
-	return xxx_messageInfo_VmResponse.Size(m)
+func (m *Firewall) XXX_Size() int {
+	return xxx_messageInfo_Firewall.Size(m)
 }
-func (m *VmResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_VmResponse.DiscardUnknown(m)
+func (m *Firewall) XXX_DiscardUnknown() {
+	xxx_messageInfo_Firewall.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_VmResponse proto.InternalMessageInfo
+var xxx_messageInfo_Firewall proto.InternalMessageInfo
 
-func (m *VmResponse) GetStatus() Status {
+func (m *Firewall) GetIngress() []*FirewallRule {
 	if m != nil {
-		return m.Status
+		return m.Ingress
 	}
-	return Status_SUCCESS
+	return nil
 }
 
-func (m *VmResponse) GetConfig() *VmConfig {
+func (m *Firewall) GetEgress() []*FirewallRule {
 	if m != nil {
-		return m.Config
+		return m.Egress
 	}
 	return nil
 }
 
-type VmList struct {
-	VmID                 []*UUID  `protobuf:"bytes,1,rep,name=vmID,proto3" json:"vmID,omitempty"`
+type FirewallRule struct {
+	// IPv4 addresses the rule allows in CIDR notation, the source of
+	// ingress and the destination of egress traffic. Any address if empty
+	Cidr string `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	// tcp, udp or icmp, any protocol if empty
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// destination ports of tcp and udp traffic, any port if fromPort is 0.
+	// toPort defaults to fromPort
+	FromPort             uint32   `protobuf:"varint,3,opt,name=fromPort,proto3" json:"fromPort,omitempty"`
+	ToPort               uint32   `protobuf:"varint,4,opt,name=toPort,proto3" json:"toPort,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *VmList) Reset()         { *m = VmList{} }
-func (m *VmList) String() string { return proto.CompactTextString(m) }
-func (*VmList) ProtoMessage()    {}
-func (*VmList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{4}
+func (m *FirewallRule) Reset()         { *m = FirewallRule{} }
+func (m *FirewallRule) String() string { return proto.CompactTextString(m) }
+func (*FirewallRule) ProtoMessage()    {}
+func (*FirewallRule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{3}
 }
 
-func (m *VmList) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_VmList.Unmarshal(m, b)
+func (m *FirewallRule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FirewallRule.Unmarshal(m, b)
 }
-func (m *VmList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_VmList.Marshal(b, m, deterministic)
+func (m *FirewallRule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FirewallRule.Marshal(b, m, deterministic)
 }
-func (m *VmList) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_VmList.Merge(m, src)
+func (m *FirewallRule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FirewallRule.Merge(m, src)
 }
-func (m *VmList) XXX_Size() int {
-	return xxx_messageInfo_VmList.Size(m)
+func (m *FirewallRule) XXX_Size() int {
+	return xxx_messageInfo_FirewallRule.Size(m)
 }
-func (m *VmList) XXX_DiscardUnknown() {
-	xxx_messageInfo_VmList.DiscardUnknown(m)
+func (m *FirewallRule) XXX_DiscardUnknown() {
+	xxx_messageInfo_FirewallRule.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_VmList proto.InternalMessageInfo
+var xxx_messageInfo_FirewallRule proto.InternalMessageInfo
 
-func (m *VmList) GetVmID() []*UUID {
+func (m *FirewallRule) GetCidr() string {
 	if m != nil {
-		return m.VmID
+		return m.Cidr
 	}
-	return nil
+	return ""
 }
 
-type ImageName struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+func (m *FirewallRule) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *FirewallRule) GetFromPort() uint32 {
+	if m != nil {
+		return m.FromPort
+	}
+	return 0
+}
+
+func (m *FirewallRule) GetToPort() uint32 {
+	if m != nil {
+		return m.ToPort
+	}
+	return 0
+}
+
+type RootfsOverlay struct {
+	// read-only base image, e.g. one built with BuildRootfs
+	BaseImage string `protobuf:"bytes,1,opt,name=baseImage,proto3" json:"baseImage,omitempty"`
+	// keeps the overlay when the VM is stopped, the next start of the VM
+	// reuses it if it is on top of the same base image
+	Keep                 bool     `protobuf:"varint,2,opt,name=keep,proto3" json:"keep,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ImageName) Reset()         { *m = ImageName{} }
-func (m *ImageName) String() string { return proto.CompactTextString(m) }
-func (*ImageName) ProtoMessage()    {}
-func (*ImageName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{5}
+func (m *RootfsOverlay) Reset()         { *m = RootfsOverlay{} }
+func (m *RootfsOverlay) String() string { return proto.CompactTextString(m) }
+func (*RootfsOverlay) ProtoMessage()    {}
+func (*RootfsOverlay) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{4}
 }
 
-func (m *ImageName) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ImageName.Unmarshal(m, b)
+func (m *RootfsOverlay) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RootfsOverlay.Unmarshal(m, b)
 }
-func (m *ImageName) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ImageName.Marshal(b, m, deterministic)
+func (m *RootfsOverlay) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RootfsOverlay.Marshal(b, m, deterministic)
 }
-func (m *ImageName) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ImageName.Merge(m, src)
+func (m *RootfsOverlay) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RootfsOverlay.Merge(m, src)
 }
-func (m *ImageName) XXX_Size() int {
-	return xxx_messageInfo_ImageName.Size(m)
+func (m *RootfsOverlay) XXX_Size() int {
+	return xxx_messageInfo_RootfsOverlay.Size(m)
 }
-func (m *ImageName) XXX_DiscardUnknown() {
-	xxx_messageInfo_ImageName.DiscardUnknown(m)
+func (m *RootfsOverlay) XXX_DiscardUnknown() {
+	xxx_messageInfo_RootfsOverlay.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ImageName proto.InternalMessageInfo
+var xxx_messageInfo_RootfsOverlay proto.InternalMessageInfo
 
-func (m *ImageName) GetName() string {
+func (m *RootfsOverlay) GetBaseImage() string {
 	if m != nil {
-		return m.Name
+		return m.BaseImage
 	}
 	return ""
 }
 
-type DriveResponse struct {
-	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
-	Size                 int64    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
-	Path                 string   `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+func (m *RootfsOverlay) GetKeep() bool {
+	if m != nil {
+		return m.Keep
+	}
+	return false
+}
+
+type RestartConfig struct {
+	Policy RestartPolicy `protobuf:"varint,1,opt,name=policy,proto3,enum=node.RestartPolicy" json:"policy,omitempty"`
+	// 0 means the VM is restarted indefinitely
+	MaxRetries           int32    `protobuf:"varint,2,opt,name=maxRetries,proto3" json:"maxRetries,omitempty"`
+	BackoffMs            int64    `protobuf:"varint,3,opt,name=backoffMs,proto3" json:"backoffMs,omitempty"`
+	MaxBackoffMs         int64    `protobuf:"varint,4,opt,name=maxBackoffMs,proto3" json:"maxBackoffMs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DriveResponse) Reset()         { *m = DriveResponse{} }
-func (m *DriveResponse) String() string { return proto.CompactTextString(m) }
-func (*DriveResponse) ProtoMessage()    {}
-func (*DriveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{6}
+func (m *RestartConfig) Reset()         { *m = RestartConfig{} }
+func (m *RestartConfig) String() string { return proto.CompactTextString(m) }
+func (*RestartConfig) ProtoMessage()    {}
+func (*RestartConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{5}
 }
 
-func (m *DriveResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DriveResponse.Unmarshal(m, b)
+func (m *RestartConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestartConfig.Unmarshal(m, b)
 }
-func (m *DriveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DriveResponse.Marshal(b, m, deterministic)
+func (m *RestartConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestartConfig.Marshal(b, m, deterministic)
 }
-func (m *DriveResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DriveResponse.Merge(m, src)
+func (m *RestartConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestartConfig.Merge(m, src)
 }
-func (m *DriveResponse) XXX_Size() int {
-	return xxx_messageInfo_DriveResponse.Size(m)
+func (m *RestartConfig) XXX_Size() int {
+	return xxx_messageInfo_RestartConfig.Size(m)
 }
-func (m *DriveResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_DriveResponse.DiscardUnknown(m)
+func (m *RestartConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestartConfig.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DriveResponse proto.InternalMessageInfo
+var xxx_messageInfo_RestartConfig proto.InternalMessageInfo
 
-func (m *DriveResponse) GetStatus() Status {
+func (m *RestartConfig) GetPolicy() RestartPolicy {
 	if m != nil {
-		return m.Status
+		return m.Policy
 	}
-	return Status_SUCCESS
+	return RestartPolicy_NEVER
 }
 
-func (m *DriveResponse) GetSize() int64 {
+func (m *RestartConfig) GetMaxRetries() int32 {
 	if m != nil {
-		return m.Size
+		return m.MaxRetries
 	}
 	return 0
 }
 
-func (m *DriveResponse) GetPath() string {
+func (m *RestartConfig) GetBackoffMs() int64 {
 	if m != nil {
-		return m.Path
+		return m.BackoffMs
 	}
-	return ""
+	return 0
 }
 
-type ConnectResponse struct {
-	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
-	Path                 string   `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *RestartConfig) GetMaxBackoffMs() int64 {
+	if m != nil {
+		return m.MaxBackoffMs
+	}
+	return 0
 }
 
-func (m *ConnectResponse) Reset()         { *m = ConnectResponse{} }
-func (m *ConnectResponse) String() string { return proto.CompactTextString(m) }
-func (*ConnectResponse) ProtoMessage()    {}
-func (*ConnectResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0c843d59d2d938e7, []int{7}
+type ReadinessProbe struct {
+	Type                 ProbeType `protobuf:"varint,1,opt,name=type,proto3,enum=node.ProbeType" json:"type,omitempty"`
+	Port                 int32     `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Path                 string    `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Marker               string    `protobuf:"bytes,4,opt,name=marker,proto3" json:"marker,omitempty"`
+	TimeoutSeconds       int64     `protobuf:"varint,5,opt,name=timeoutSeconds,proto3" json:"timeoutSeconds,omitempty"`
+	IntervalMs           int64     `protobuf:"varint,6,opt,name=intervalMs,proto3" json:"intervalMs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
-func (m *ConnectResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ConnectResponse.Unmarshal(m, b)
+func (m *ReadinessProbe) Reset()         { *m = ReadinessProbe{} }
+func (m *ReadinessProbe) String() string { return proto.CompactTextString(m) }
+func (*ReadinessProbe) ProtoMessage()    {}
+func (*ReadinessProbe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{6}
 }
-func (m *ConnectResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ConnectResponse.Marshal(b, m, deterministic)
+
+func (m *ReadinessProbe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadinessProbe.Unmarshal(m, b)
 }
-func (m *ConnectResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ConnectResponse.Merge(m, src)
+func (m *ReadinessProbe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadinessProbe.Marshal(b, m, deterministic)
 }
-func (m *ConnectResponse) XXX_Size() int {
-	return xxx_messageInfo_ConnectResponse.Size(m)
+func (m *ReadinessProbe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadinessProbe.Merge(m, src)
 }
-func (m *ConnectResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ConnectResponse.DiscardUnknown(m)
+func (m *ReadinessProbe) XXX_Size() int {
+	return xxx_messageInfo_ReadinessProbe.Size(m)
+}
+func (m *ReadinessProbe) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadinessProbe.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ConnectResponse proto.InternalMessageInfo
+var xxx_messageInfo_ReadinessProbe proto.InternalMessageInfo
 
-func (m *ConnectResponse) GetStatus() Status {
+func (m *ReadinessProbe) GetType() ProbeType {
 	if m != nil {
-		return m.Status
+		return m.Type
 	}
-	return Status_SUCCESS
+	return ProbeType_NONE
 }
 
-func (m *ConnectResponse) GetPath() string {
+func (m *ReadinessProbe) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *ReadinessProbe) GetPath() string {
 	if m != nil {
 		return m.Path
 	}
 	return ""
 }
 
-type Volume struct {
-	VolumeID             string   `protobuf:"bytes,1,opt,name=volumeID,proto3" json:"volumeID,omitempty"`
-	PoolName             string   `protobuf:"bytes,2,opt,name=poolName,proto3" json:"poolName,omitempty"`
-	ImagePath            string   `protobuf:"bytes,3,opt,name=imagePath,proto3" json:"imagePath,omitempty"`
+func (m *ReadinessProbe) GetMarker() string {
+	if m != nil {
+		return m.Marker
+	}
+	return ""
+}
+
+func (m *ReadinessProbe) GetTimeoutSeconds() int64 {
+	if m != nil {
+		return m.TimeoutSeconds
+	}
+	return 0
+}
+
+func (m *ReadinessProbe) GetIntervalMs() int64 {
+	if m != nil {
+		return m.IntervalMs
+	}
+	return 0
+}
+
+type BalloonConfig struct {
+	AmountMib             int64    `protobuf:"varint,1,opt,name=amountMib,proto3" json:"amountMib,omitempty"`
+	DeflateOnOom          bool     `protobuf:"varint,2,opt,name=deflateOnOom,proto3" json:"deflateOnOom,omitempty"`
+	StatsPollingIntervalS int64    `protobuf:"varint,3,opt,name=statsPollingIntervalS,proto3" json:"statsPollingIntervalS,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *BalloonConfig) Reset()         { *m = BalloonConfig{} }
+func (m *BalloonConfig) String() string { return proto.CompactTextString(m) }
+func (*BalloonConfig) ProtoMessage()    {}
+func (*BalloonConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{7}
+}
+
+func (m *BalloonConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BalloonConfig.Unmarshal(m, b)
+}
+func (m *BalloonConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BalloonConfig.Marshal(b, m, deterministic)
+}
+func (m *BalloonConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BalloonConfig.Merge(m, src)
+}
+func (m *BalloonConfig) XXX_Size() int {
+	return xxx_messageInfo_BalloonConfig.Size(m)
+}
+func (m *BalloonConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_BalloonConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BalloonConfig proto.InternalMessageInfo
+
+func (m *BalloonConfig) GetAmountMib() int64 {
+	if m != nil {
+		return m.AmountMib
+	}
+	return 0
+}
+
+func (m *BalloonConfig) GetDeflateOnOom() bool {
+	if m != nil {
+		return m.DeflateOnOom
+	}
+	return false
+}
+
+func (m *BalloonConfig) GetStatsPollingIntervalS() int64 {
+	if m != nil {
+		return m.StatsPollingIntervalS
+	}
+	return 0
+}
+
+type BalloonTarget struct {
+	VmID                 *UUID    `protobuf:"bytes,1,opt,name=vmID,proto3" json:"vmID,omitempty"`
+	TargetMib            int64    `protobuf:"varint,2,opt,name=targetMib,proto3" json:"targetMib,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Volume) Reset()         { *m = Volume{} }
-func (m *Volume) String() string { return proto.CompactTextString(m) }
-func (*Volume) ProtoMessage()    {}
-func (*Volume) Descriptor() ([]byte, []int) {
+func (m *BalloonTarget) Reset()         { *m = BalloonTarget{} }
+func (m *BalloonTarget) String() string { return proto.CompactTextString(m) }
+func (*BalloonTarget) ProtoMessage()    {}
+func (*BalloonTarget) Descriptor() ([]byte, []int) {
 	return fileDescriptor_0c843d59d2d938e7, []int{8}
 }
 
-func (m *Volume) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Volume.Unmarshal(m, b)
+func (m *BalloonTarget) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BalloonTarget.Unmarshal(m, b)
 }
-func (m *Volume) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Volume.Marshal(b, m, deterministic)
+func (m *BalloonTarget) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BalloonTarget.Marshal(b, m, deterministic)
 }
-func (m *Volume) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Volume.Merge(m, src)
+func (m *BalloonTarget) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BalloonTarget.Merge(m, src)
 }
-func (m *Volume) XXX_Size() int {
-	return xxx_messageInfo_Volume.Size(m)
+func (m *BalloonTarget) XXX_Size() int {
+	return xxx_messageInfo_BalloonTarget.Size(m)
 }
-func (m *Volume) XXX_DiscardUnknown() {
-	xxx_messageInfo_Volume.DiscardUnknown(m)
+func (m *BalloonTarget) XXX_DiscardUnknown() {
+	xxx_messageInfo_BalloonTarget.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Volume proto.InternalMessageInfo
+var xxx_messageInfo_BalloonTarget proto.InternalMessageInfo
 
-func (m *Volume) GetVolumeID() string {
+func (m *BalloonTarget) GetVmID() *UUID {
 	if m != nil {
-		return m.VolumeID
+		return m.VmID
 	}
-	return ""
+	return nil
 }
 
-func (m *Volume) GetPoolName() string {
+func (m *BalloonTarget) GetTargetMib() int64 {
 	if m != nil {
-		return m.PoolName
+		return m.TargetMib
 	}
-	return ""
+	return 0
 }
 
-func (m *Volume) GetImagePath() string {
+type BalloonStats struct {
+	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	TargetMib            int64    `protobuf:"varint,2,opt,name=targetMib,proto3" json:"targetMib,omitempty"`
+	ActualMib            int64    `protobuf:"varint,3,opt,name=actualMib,proto3" json:"actualMib,omitempty"`
+	TargetPages          int64    `protobuf:"varint,4,opt,name=targetPages,proto3" json:"targetPages,omitempty"`
+	ActualPages          int64    `protobuf:"varint,5,opt,name=actualPages,proto3" json:"actualPages,omitempty"`
+	SwapIn               int64    `protobuf:"varint,6,opt,name=swapIn,proto3" json:"swapIn,omitempty"`
+	SwapOut              int64    `protobuf:"varint,7,opt,name=swapOut,proto3" json:"swapOut,omitempty"`
+	MajorFaults          int64    `protobuf:"varint,8,opt,name=majorFaults,proto3" json:"majorFaults,omitempty"`
+	MinorFaults          int64    `protobuf:"varint,9,opt,name=minorFaults,proto3" json:"minorFaults,omitempty"`
+	FreeMemory           int64    `protobuf:"varint,10,opt,name=freeMemory,proto3" json:"freeMemory,omitempty"`
+	TotalMemory          int64    `protobuf:"varint,11,opt,name=totalMemory,proto3" json:"totalMemory,omitempty"`
+	AvailableMemory      int64    `protobuf:"varint,12,opt,name=availableMemory,proto3" json:"availableMemory,omitempty"`
+	DiskCaches           int64    `protobuf:"varint,13,opt,name=diskCaches,proto3" json:"diskCaches,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BalloonStats) Reset()         { *m = BalloonStats{} }
+func (m *BalloonStats) String() string { return proto.CompactTextString(m) }
+func (*BalloonStats) ProtoMessage()    {}
+func (*BalloonStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{9}
+}
+
+func (m *BalloonStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BalloonStats.Unmarshal(m, b)
+}
+func (m *BalloonStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BalloonStats.Marshal(b, m, deterministic)
+}
+func (m *BalloonStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BalloonStats.Merge(m, src)
+}
+func (m *BalloonStats) XXX_Size() int {
+	return xxx_messageInfo_BalloonStats.Size(m)
+}
+func (m *BalloonStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_BalloonStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BalloonStats proto.InternalMessageInfo
+
+func (m *BalloonStats) GetStatus() Status {
 	if m != nil {
-		return m.ImagePath
+		return m.Status
 	}
-	return ""
+	return Status_SUCCESS
 }
 
-func init() {
-	proto.RegisterEnum("node.Status", Status_name, Status_value)
-	proto.RegisterType((*UUID)(nil), "node.UUID")
-	proto.RegisterType((*VmConfig)(nil), "node.VmConfig")
-	proto.RegisterType((*Response)(nil), "node.Response")
-	proto.RegisterType((*VmResponse)(nil), "node.VmResponse")
-	proto.RegisterType((*VmList)(nil), "node.VmList")
-	proto.RegisterType((*ImageName)(nil), "node.ImageName")
-	proto.RegisterType((*DriveResponse)(nil), "node.DriveResponse")
-	proto.RegisterType((*ConnectResponse)(nil), "node.ConnectResponse")
-	proto.RegisterType((*Volume)(nil), "node.Volume")
+func (m *BalloonStats) GetTargetMib() int64 {
+	if m != nil {
+		return m.TargetMib
+	}
+	return 0
 }
 
-func init() { proto.RegisterFile("node.proto", fileDescriptor_0c843d59d2d938e7) }
+func (m *BalloonStats) GetActualMib() int64 {
+	if m != nil {
+		return m.ActualMib
+	}
+	return 0
+}
 
-var fileDescriptor_0c843d59d2d938e7 = []byte{
-	// 523 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x61, 0x8f, 0xd2, 0x40,
-	0x10, 0xa5, 0xd2, 0x2b, 0x74, 0xb8, 0xe3, 0xc8, 0xa8, 0x97, 0xa6, 0x5e, 0x14, 0x37, 0xe6, 0x42,
-	0x4c, 0xe4, 0x14, 0xa3, 0xdf, 0x0d, 0x70, 0x09, 0xf1, 0xb8, 0x98, 0x36, 0xf4, 0x83, 0x89, 0x26,
-	0x3d, 0xd8, 0xc3, 0xc6, 0x6e, 0xb7, 0xe9, 0x2e, 0x24, 0xf8, 0xdb, 0xfc, 0x6f, 0x9a, 0x4e, 0x5b,
-	0x0e, 0xf1, 0xbe, 0xf0, 0x6d, 0xde, 0x9b, 0xd7, 0xd9, 0xd9, 0xf7, 0xba, 0x00, 0x89, 0x5c, 0xf0,
-	0x7e, 0x9a, 0x49, 0x2d, 0xd1, 0xcc, 0x6b, 0xf7, 0xd9, 0x52, 0xca, 0x65, 0xcc, 0x2f, 0x89, 0xbb,
-	0x5d, 0xdd, 0x5d, 0x72, 0x91, 0xea, 0x4d, 0x21, 0x61, 0xe7, 0x60, 0xce, 0x66, 0x93, 0x11, 0x3e,
-	0x81, 0xa3, 0x75, 0x18, 0xaf, 0xb8, 0x63, 0x74, 0x8d, 0x9e, 0xed, 0x15, 0x80, 0xfd, 0x36, 0xa0,
-	0x19, 0x88, 0xa1, 0x4c, 0xee, 0xa2, 0x25, 0x3e, 0x07, 0x73, 0x2d, 0x26, 0x23, 0x52, 0xb4, 0x06,
-	0xd0, 0xa7, 0x83, 0xf2, 0x8f, 0x3d, 0xe2, 0xf1, 0x0c, 0x2c, 0xc1, 0x85, 0xcc, 0x36, 0xce, 0xa3,
-	0xae, 0xd1, 0xab, 0x7b, 0x25, 0xa2, 0xd1, 0xf3, 0x74, 0xa5, 0x9c, 0x3a, 0xd1, 0x05, 0xc0, 0x2e,
-	0xb4, 0x7e, 0xf2, 0x2c, 0xe1, 0xf1, 0x44, 0x84, 0x4b, 0xee, 0x98, 0x74, 0xec, 0x2e, 0x85, 0x17,
-	0xd0, 0xce, 0xa4, 0xd4, 0x57, 0x51, 0xcc, 0xfd, 0x8d, 0xd2, 0x5c, 0x38, 0x47, 0x24, 0xda, 0x63,
-	0xd1, 0x81, 0x46, 0xb8, 0x58, 0x64, 0x5c, 0x29, 0xc7, 0x22, 0x41, 0x05, 0xd9, 0x5b, 0x68, 0x7a,
-	0x5c, 0xa5, 0x32, 0x51, 0x1c, 0x5f, 0x81, 0xa5, 0x74, 0xa8, 0x57, 0x8a, 0xf6, 0x6f, 0x0f, 0x8e,
-	0x8b, 0xfd, 0x7d, 0xe2, 0xbc, 0xb2, 0xc7, 0xbe, 0x02, 0x04, 0xe2, 0xb0, 0x6f, 0xf0, 0x02, 0xac,
-	0x39, 0x39, 0x44, 0xf7, 0x6e, 0x0d, 0xda, 0x85, 0xaa, 0xf2, 0xcd, 0x2b, 0xbb, 0xac, 0x07, 0x56,
-	0x20, 0xae, 0x23, 0xa5, 0x77, 0x9c, 0xac, 0x3f, 0xe4, 0x24, 0x7b, 0x01, 0x36, 0x59, 0x70, 0x13,
-	0x0a, 0x8e, 0x08, 0x66, 0x12, 0x8a, 0x2a, 0x18, 0xaa, 0xd9, 0x37, 0x38, 0x19, 0x65, 0xd1, 0x9a,
-	0x1f, 0xb8, 0x29, 0x82, 0xa9, 0xa2, 0x5f, 0xbc, 0xcc, 0x87, 0xea, 0x9c, 0x4b, 0x43, 0xfd, 0x83,
-	0xc2, 0xb1, 0x3d, 0xaa, 0xd9, 0x67, 0x38, 0x1d, 0xca, 0x24, 0xe1, 0x73, 0x7d, 0xf8, 0x01, 0xff,
-	0x0d, 0xfb, 0x0e, 0x56, 0x20, 0xe3, 0x95, 0xe0, 0xe8, 0x42, 0x73, 0x4d, 0x55, 0xf9, 0x13, 0xd9,
-	0xde, 0x16, 0xe7, 0xbd, 0x54, 0xca, 0x38, 0xbf, 0x31, 0xad, 0x67, 0x7b, 0x5b, 0x8c, 0xe7, 0x60,
-	0x47, 0xb9, 0x1d, 0x5f, 0xee, 0x47, 0xdf, 0x13, 0xaf, 0x5f, 0x82, 0x55, 0x6c, 0x81, 0x2d, 0x68,
-	0xf8, 0xb3, 0xe1, 0x70, 0xec, 0xfb, 0x9d, 0x1a, 0x02, 0x58, 0x57, 0x9f, 0x26, 0xd7, 0xe3, 0x51,
-	0xc7, 0x18, 0xfc, 0x31, 0xc0, 0xbc, 0x91, 0x0b, 0x8e, 0x6f, 0xa0, 0xe1, 0xeb, 0x30, 0xd3, 0xc1,
-	0x14, 0xf7, 0x52, 0x72, 0x3b, 0x15, 0xae, 0xae, 0xcc, 0x6a, 0x79, 0xb2, 0xbe, 0x96, 0x69, 0x30,
-	0xc5, 0x9d, 0x8c, 0xdc, 0xf2, 0xcb, 0x1d, 0xdd, 0x3b, 0x68, 0xe4, 0xb9, 0x06, 0x53, 0x85, 0x67,
-	0xfd, 0xe2, 0xb5, 0xf5, 0xab, 0xd7, 0xd6, 0x1f, 0xe7, 0xaf, 0xcd, 0x3d, 0xae, 0xc6, 0xe7, 0x42,
-	0x56, 0xc3, 0x0f, 0xd0, 0x1a, 0x66, 0x3c, 0xd4, 0x9c, 0x72, 0xc4, 0xd3, 0xa2, 0xbd, 0x4d, 0xdd,
-	0x7d, 0x5c, 0x10, 0xff, 0xa4, 0xcc, 0x6a, 0xf8, 0x11, 0x4e, 0xca, 0x64, 0x4a, 0x4f, 0xab, 0xb9,
-	0x84, 0xdc, 0xa7, 0x05, 0xda, 0x0b, 0x8f, 0xd5, 0x6e, 0x2d, 0x5a, 0xe7, 0xfd, 0xdf, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0x6d, 0x6f, 0x9e, 0xef, 0x1e, 0x04, 0x00, 0x00,
+func (m *BalloonStats) GetTargetPages() int64 {
+	if m != nil {
+		return m.TargetPages
+	}
+	return 0
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *BalloonStats) GetActualPages() int64 {
+	if m != nil {
+		return m.ActualPages
+	}
+	return 0
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *BalloonStats) GetSwapIn() int64 {
+	if m != nil {
+		return m.SwapIn
+	}
+	return 0
+}
 
-// NodeClient is the client API for Node service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type NodeClient interface {
-	StartVM(ctx context.Context, in *VmConfig, opts ...grpc.CallOption) (*VmResponse, error)
-	StopVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
-	ListVMs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VmList, error)
-	CreateDrive(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*DriveResponse, error)
-	ConnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*ConnectResponse, error)
+func (m *BalloonStats) GetSwapOut() int64 {
+	if m != nil {
+		return m.SwapOut
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetMajorFaults() int64 {
+	if m != nil {
+		return m.MajorFaults
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetMinorFaults() int64 {
+	if m != nil {
+		return m.MinorFaults
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetFreeMemory() int64 {
+	if m != nil {
+		return m.FreeMemory
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetTotalMemory() int64 {
+	if m != nil {
+		return m.TotalMemory
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetAvailableMemory() int64 {
+	if m != nil {
+		return m.AvailableMemory
+	}
+	return 0
+}
+
+func (m *BalloonStats) GetDiskCaches() int64 {
+	if m != nil {
+		return m.DiskCaches
+	}
+	return 0
+}
+
+type Response struct {
+	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+func (*Response) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{10}
+}
+
+func (m *Response) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Response.Unmarshal(m, b)
+}
+func (m *Response) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Response.Marshal(b, m, deterministic)
+}
+func (m *Response) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Response.Merge(m, src)
+}
+func (m *Response) XXX_Size() int {
+	return xxx_messageInfo_Response.Size(m)
+}
+func (m *Response) XXX_DiscardUnknown() {
+	xxx_messageInfo_Response.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Response proto.InternalMessageInfo
+
+func (m *Response) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+type VmResponse struct {
+	Status               Status    `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	Config               *VmConfig `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *VmResponse) Reset()         { *m = VmResponse{} }
+func (m *VmResponse) String() string { return proto.CompactTextString(m) }
+func (*VmResponse) ProtoMessage()    {}
+func (*VmResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{11}
+}
+
+func (m *VmResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VmResponse.Unmarshal(m, b)
+}
+func (m *VmResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VmResponse.Marshal(b, m, deterministic)
+}
+func (m *VmResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VmResponse.Merge(m, src)
+}
+func (m *VmResponse) XXX_Size() int {
+	return xxx_messageInfo_VmResponse.Size(m)
+}
+func (m *VmResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VmResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VmResponse proto.InternalMessageInfo
+
+func (m *VmResponse) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+func (m *VmResponse) GetConfig() *VmConfig {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type VmInfo struct {
+	VmID                 *UUID     `protobuf:"bytes,1,opt,name=vmID,proto3" json:"vmID,omitempty"`
+	State                VmState   `protobuf:"varint,2,opt,name=state,proto3,enum=node.VmState" json:"state,omitempty"`
+	Config               *VmConfig `protobuf:"bytes,3,opt,name=config,proto3" json:"config,omitempty"`
+	ExitStatus           string    `protobuf:"bytes,4,opt,name=exitStatus,proto3" json:"exitStatus,omitempty"`
+	Restarts             int32     `protobuf:"varint,5,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *VmInfo) Reset()         { *m = VmInfo{} }
+func (m *VmInfo) String() string { return proto.CompactTextString(m) }
+func (*VmInfo) ProtoMessage()    {}
+func (*VmInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{12}
+}
+
+func (m *VmInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VmInfo.Unmarshal(m, b)
+}
+func (m *VmInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VmInfo.Marshal(b, m, deterministic)
+}
+func (m *VmInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VmInfo.Merge(m, src)
+}
+func (m *VmInfo) XXX_Size() int {
+	return xxx_messageInfo_VmInfo.Size(m)
+}
+func (m *VmInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_VmInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VmInfo proto.InternalMessageInfo
+
+func (m *VmInfo) GetVmID() *UUID {
+	if m != nil {
+		return m.VmID
+	}
+	return nil
+}
+
+func (m *VmInfo) GetState() VmState {
+	if m != nil {
+		return m.State
+	}
+	return VmState_UNKNOWN
+}
+
+func (m *VmInfo) GetConfig() *VmConfig {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+func (m *VmInfo) GetExitStatus() string {
+	if m != nil {
+		return m.ExitStatus
+	}
+	return ""
+}
+
+func (m *VmInfo) GetRestarts() int32 {
+	if m != nil {
+		return m.Restarts
+	}
+	return 0
+}
+
+type VmList struct {
+	VmID                 []*UUID   `protobuf:"bytes,1,rep,name=vmID,proto3" json:"vmID,omitempty"`
+	Vms                  []*VmInfo `protobuf:"bytes,2,rep,name=vms,proto3" json:"vms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *VmList) Reset()         { *m = VmList{} }
+func (m *VmList) String() string { return proto.CompactTextString(m) }
+func (*VmList) ProtoMessage()    {}
+func (*VmList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{13}
+}
+
+func (m *VmList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VmList.Unmarshal(m, b)
+}
+func (m *VmList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VmList.Marshal(b, m, deterministic)
+}
+func (m *VmList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VmList.Merge(m, src)
+}
+func (m *VmList) XXX_Size() int {
+	return xxx_messageInfo_VmList.Size(m)
+}
+func (m *VmList) XXX_DiscardUnknown() {
+	xxx_messageInfo_VmList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VmList proto.InternalMessageInfo
+
+func (m *VmList) GetVmID() []*UUID {
+	if m != nil {
+		return m.VmID
+	}
+	return nil
+}
+
+func (m *VmList) GetVms() []*VmInfo {
+	if m != nil {
+		return m.Vms
+	}
+	return nil
+}
+
+type RegistryCredentials struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	// name of credentials defined in the node config
+	Ref                  string   `protobuf:"bytes,3,opt,name=ref,proto3" json:"ref,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegistryCredentials) Reset()         { *m = RegistryCredentials{} }
+func (m *RegistryCredentials) String() string { return proto.CompactTextString(m) }
+func (*RegistryCredentials) ProtoMessage()    {}
+func (*RegistryCredentials) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{14}
+}
+
+func (m *RegistryCredentials) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegistryCredentials.Unmarshal(m, b)
+}
+func (m *RegistryCredentials) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegistryCredentials.Marshal(b, m, deterministic)
+}
+func (m *RegistryCredentials) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegistryCredentials.Merge(m, src)
+}
+func (m *RegistryCredentials) XXX_Size() int {
+	return xxx_messageInfo_RegistryCredentials.Size(m)
+}
+func (m *RegistryCredentials) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegistryCredentials.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegistryCredentials proto.InternalMessageInfo
+
+func (m *RegistryCredentials) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *RegistryCredentials) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *RegistryCredentials) GetRef() string {
+	if m != nil {
+		return m.Ref
+	}
+	return ""
+}
+
+type ImageName struct {
+	// registry reference, or transport:reference for any other transport,
+	// e.g. oci-archive:/srv/images/alpine.tar
+	Name                 string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Credentials          *RegistryCredentials `protobuf:"bytes,2,opt,name=credentials,proto3" json:"credentials,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ImageName) Reset()         { *m = ImageName{} }
+func (m *ImageName) String() string { return proto.CompactTextString(m) }
+func (*ImageName) ProtoMessage()    {}
+func (*ImageName) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{15}
+}
+
+func (m *ImageName) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImageName.Unmarshal(m, b)
+}
+func (m *ImageName) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImageName.Marshal(b, m, deterministic)
+}
+func (m *ImageName) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImageName.Merge(m, src)
+}
+func (m *ImageName) XXX_Size() int {
+	return xxx_messageInfo_ImageName.Size(m)
+}
+func (m *ImageName) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImageName.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImageName proto.InternalMessageInfo
+
+func (m *ImageName) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ImageName) GetCredentials() *RegistryCredentials {
+	if m != nil {
+		return m.Credentials
+	}
+	return nil
+}
+
+type LayerProgress struct {
+	Digest string `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Size   int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// bytes copied so far, layers already in the store are not reported
+	// until the pull is done
+	Offset               int64    `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LayerProgress) Reset()         { *m = LayerProgress{} }
+func (m *LayerProgress) String() string { return proto.CompactTextString(m) }
+func (*LayerProgress) ProtoMessage()    {}
+func (*LayerProgress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{16}
+}
+
+func (m *LayerProgress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LayerProgress.Unmarshal(m, b)
+}
+func (m *LayerProgress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LayerProgress.Marshal(b, m, deterministic)
+}
+func (m *LayerProgress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LayerProgress.Merge(m, src)
+}
+func (m *LayerProgress) XXX_Size() int {
+	return xxx_messageInfo_LayerProgress.Size(m)
+}
+func (m *LayerProgress) XXX_DiscardUnknown() {
+	xxx_messageInfo_LayerProgress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LayerProgress proto.InternalMessageInfo
+
+func (m *LayerProgress) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *LayerProgress) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *LayerProgress) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type Operation struct {
+	Id     *UUID            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State  OperationState   `protobuf:"varint,2,opt,name=state,proto3,enum=node.OperationState" json:"state,omitempty"`
+	Image  string           `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Layers []*LayerProgress `protobuf:"bytes,4,rep,name=layers,proto3" json:"layers,omitempty"`
+	Error  string           `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// path and sizes of the pulled image once the operation is done, as
+	// in DriveResponse
+	Path            string `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
+	Size            int64  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	UnpackedSize    int64  `protobuf:"varint,8,opt,name=unpackedSize,proto3" json:"unpackedSize,omitempty"`
+	RequiredSizeMib int64  `protobuf:"varint,9,opt,name=requiredSizeMib,proto3" json:"requiredSizeMib,omitempty"`
+	// volume released by ReleaseVolume and the progress of its erasure
+	VolumeID             string   `protobuf:"bytes,10,opt,name=volumeID,proto3" json:"volumeID,omitempty"`
+	BytesErased          int64    `protobuf:"varint,11,opt,name=bytesErased,proto3" json:"bytesErased,omitempty"`
+	BytesTotal           int64    `protobuf:"varint,12,opt,name=bytesTotal,proto3" json:"bytesTotal,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Operation) Reset()         { *m = Operation{} }
+func (m *Operation) String() string { return proto.CompactTextString(m) }
+func (*Operation) ProtoMessage()    {}
+func (*Operation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{17}
+}
+
+func (m *Operation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Operation.Unmarshal(m, b)
+}
+func (m *Operation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Operation.Marshal(b, m, deterministic)
+}
+func (m *Operation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operation.Merge(m, src)
+}
+func (m *Operation) XXX_Size() int {
+	return xxx_messageInfo_Operation.Size(m)
+}
+func (m *Operation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Operation proto.InternalMessageInfo
+
+func (m *Operation) GetId() *UUID {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *Operation) GetState() OperationState {
+	if m != nil {
+		return m.State
+	}
+	return OperationState_PENDING
+}
+
+func (m *Operation) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *Operation) GetLayers() []*LayerProgress {
+	if m != nil {
+		return m.Layers
+	}
+	return nil
+}
+
+func (m *Operation) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Operation) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Operation) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Operation) GetUnpackedSize() int64 {
+	if m != nil {
+		return m.UnpackedSize
+	}
+	return 0
+}
+
+func (m *Operation) GetRequiredSizeMib() int64 {
+	if m != nil {
+		return m.RequiredSizeMib
+	}
+	return 0
+}
+
+func (m *Operation) GetVolumeID() string {
+	if m != nil {
+		return m.VolumeID
+	}
+	return ""
+}
+
+func (m *Operation) GetBytesErased() int64 {
+	if m != nil {
+		return m.BytesErased
+	}
+	return 0
+}
+
+func (m *Operation) GetBytesTotal() int64 {
+	if m != nil {
+		return m.BytesTotal
+	}
+	return 0
+}
+
+type ImageInfo struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Digest               string   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImageInfo) Reset()         { *m = ImageInfo{} }
+func (m *ImageInfo) String() string { return proto.CompactTextString(m) }
+func (*ImageInfo) ProtoMessage()    {}
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{18}
+}
+
+func (m *ImageInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImageInfo.Unmarshal(m, b)
+}
+func (m *ImageInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImageInfo.Marshal(b, m, deterministic)
+}
+func (m *ImageInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImageInfo.Merge(m, src)
+}
+func (m *ImageInfo) XXX_Size() int {
+	return xxx_messageInfo_ImageInfo.Size(m)
+}
+func (m *ImageInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImageInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImageInfo proto.InternalMessageInfo
+
+func (m *ImageInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ImageInfo) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *ImageInfo) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type ImageList struct {
+	Images               []*ImageInfo `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	Usage                int64        `protobuf:"varint,2,opt,name=usage,proto3" json:"usage,omitempty"`
+	Quota                int64        `protobuf:"varint,3,opt,name=quota,proto3" json:"quota,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ImageList) Reset()         { *m = ImageList{} }
+func (m *ImageList) String() string { return proto.CompactTextString(m) }
+func (*ImageList) ProtoMessage()    {}
+func (*ImageList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{19}
+}
+
+func (m *ImageList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImageList.Unmarshal(m, b)
+}
+func (m *ImageList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImageList.Marshal(b, m, deterministic)
+}
+func (m *ImageList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImageList.Merge(m, src)
+}
+func (m *ImageList) XXX_Size() int {
+	return xxx_messageInfo_ImageList.Size(m)
+}
+func (m *ImageList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImageList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImageList proto.InternalMessageInfo
+
+func (m *ImageList) GetImages() []*ImageInfo {
+	if m != nil {
+		return m.Images
+	}
+	return nil
+}
+
+func (m *ImageList) GetUsage() int64 {
+	if m != nil {
+		return m.Usage
+	}
+	return 0
+}
+
+func (m *ImageList) GetQuota() int64 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+type DriveResponse struct {
+	Status Status `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	// size of the compressed layers
+	Size int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Path string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	// size of the files once unpacked
+	UnpackedSize int64 `protobuf:"varint,4,opt,name=unpackedSize,proto3" json:"unpackedSize,omitempty"`
+	// size of a volume the image fits on, with headroom
+	RequiredSizeMib      int64    `protobuf:"varint,5,opt,name=requiredSizeMib,proto3" json:"requiredSizeMib,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DriveResponse) Reset()         { *m = DriveResponse{} }
+func (m *DriveResponse) String() string { return proto.CompactTextString(m) }
+func (*DriveResponse) ProtoMessage()    {}
+func (*DriveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{20}
+}
+
+func (m *DriveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DriveResponse.Unmarshal(m, b)
+}
+func (m *DriveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DriveResponse.Marshal(b, m, deterministic)
+}
+func (m *DriveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DriveResponse.Merge(m, src)
+}
+func (m *DriveResponse) XXX_Size() int {
+	return xxx_messageInfo_DriveResponse.Size(m)
+}
+func (m *DriveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DriveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DriveResponse proto.InternalMessageInfo
+
+func (m *DriveResponse) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+func (m *DriveResponse) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *DriveResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *DriveResponse) GetUnpackedSize() int64 {
+	if m != nil {
+		return m.UnpackedSize
+	}
+	return 0
+}
+
+func (m *DriveResponse) GetRequiredSizeMib() int64 {
+	if m != nil {
+		return m.RequiredSizeMib
+	}
+	return 0
+}
+
+type ConnectResponse struct {
+	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	Path                 string   `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConnectResponse) Reset()         { *m = ConnectResponse{} }
+func (m *ConnectResponse) String() string { return proto.CompactTextString(m) }
+func (*ConnectResponse) ProtoMessage()    {}
+func (*ConnectResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{21}
+}
+
+func (m *ConnectResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnectResponse.Unmarshal(m, b)
+}
+func (m *ConnectResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnectResponse.Marshal(b, m, deterministic)
+}
+func (m *ConnectResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnectResponse.Merge(m, src)
+}
+func (m *ConnectResponse) XXX_Size() int {
+	return xxx_messageInfo_ConnectResponse.Size(m)
+}
+func (m *ConnectResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnectResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConnectResponse proto.InternalMessageInfo
+
+func (m *ConnectResponse) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+func (m *ConnectResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+type Volume struct {
+	VolumeID  string `protobuf:"bytes,1,opt,name=volumeID,proto3" json:"volumeID,omitempty"`
+	PoolName  string `protobuf:"bytes,2,opt,name=poolName,proto3" json:"poolName,omitempty"`
+	ImagePath string `protobuf:"bytes,3,opt,name=imagePath,proto3" json:"imagePath,omitempty"`
+	Backend   string `protobuf:"bytes,4,opt,name=backend,proto3" json:"backend,omitempty"`
+	// volumes populated from an image are sized for it if 0
+	SizeMib int64       `protobuf:"varint,5,opt,name=sizeMib,proto3" json:"sizeMib,omitempty"`
+	Mode    ConnectMode `protobuf:"varint,6,opt,name=mode,proto3,enum=node.ConnectMode" json:"mode,omitempty"`
+	FsType  string      `protobuf:"bytes,7,opt,name=fsType,proto3" json:"fsType,omitempty"`
+	// allows formatting a volume that already has a filesystem
+	Force bool `protobuf:"varint,8,opt,name=force,proto3" json:"force,omitempty"`
+	// written after the image is unpacked
+	Files []*FileInjection `protobuf:"bytes,9,rep,name=files,proto3" json:"files,omitempty"`
+	// how the data is erased when the volume is disconnected, the mode
+	// given on connect applies to every disconnect that doesn't ask for one
+	Erase                EraseMode `protobuf:"varint,10,opt,name=erase,proto3,enum=node.EraseMode" json:"erase,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Volume) Reset()         { *m = Volume{} }
+func (m *Volume) String() string { return proto.CompactTextString(m) }
+func (*Volume) ProtoMessage()    {}
+func (*Volume) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{22}
+}
+
+func (m *Volume) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Volume.Unmarshal(m, b)
+}
+func (m *Volume) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Volume.Marshal(b, m, deterministic)
+}
+func (m *Volume) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Volume.Merge(m, src)
+}
+func (m *Volume) XXX_Size() int {
+	return xxx_messageInfo_Volume.Size(m)
+}
+func (m *Volume) XXX_DiscardUnknown() {
+	xxx_messageInfo_Volume.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Volume proto.InternalMessageInfo
+
+func (m *Volume) GetVolumeID() string {
+	if m != nil {
+		return m.VolumeID
+	}
+	return ""
+}
+
+func (m *Volume) GetPoolName() string {
+	if m != nil {
+		return m.PoolName
+	}
+	return ""
+}
+
+func (m *Volume) GetImagePath() string {
+	if m != nil {
+		return m.ImagePath
+	}
+	return ""
+}
+
+func (m *Volume) GetBackend() string {
+	if m != nil {
+		return m.Backend
+	}
+	return ""
+}
+
+func (m *Volume) GetSizeMib() int64 {
+	if m != nil {
+		return m.SizeMib
+	}
+	return 0
+}
+
+func (m *Volume) GetMode() ConnectMode {
+	if m != nil {
+		return m.Mode
+	}
+	return ConnectMode_FORMAT_AND_POPULATE
+}
+
+func (m *Volume) GetFsType() string {
+	if m != nil {
+		return m.FsType
+	}
+	return ""
+}
+
+func (m *Volume) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+func (m *Volume) GetFiles() []*FileInjection {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *Volume) GetErase() EraseMode {
+	if m != nil {
+		return m.Erase
+	}
+	return EraseMode_KEEP_DATA
+}
+
+type FileInjection struct {
+	// path inside the guest, symlinks are resolved within the guest
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// permission bits, 0644 if 0
+	Mode                 uint32   `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Uid                  uint32   `protobuf:"varint,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	Gid                  uint32   `protobuf:"varint,4,opt,name=gid,proto3" json:"gid,omitempty"`
+	Content              []byte   `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileInjection) Reset()         { *m = FileInjection{} }
+func (m *FileInjection) String() string { return proto.CompactTextString(m) }
+func (*FileInjection) ProtoMessage()    {}
+func (*FileInjection) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{23}
+}
+
+func (m *FileInjection) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileInjection.Unmarshal(m, b)
+}
+func (m *FileInjection) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileInjection.Marshal(b, m, deterministic)
+}
+func (m *FileInjection) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileInjection.Merge(m, src)
+}
+func (m *FileInjection) XXX_Size() int {
+	return xxx_messageInfo_FileInjection.Size(m)
+}
+func (m *FileInjection) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileInjection.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileInjection proto.InternalMessageInfo
+
+func (m *FileInjection) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FileInjection) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *FileInjection) GetUid() uint32 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *FileInjection) GetGid() uint32 {
+	if m != nil {
+		return m.Gid
+	}
+	return 0
+}
+
+func (m *FileInjection) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+type ResizeRequest struct {
+	Volume               *Volume  `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"`
+	SizeMib              int64    `protobuf:"varint,2,opt,name=sizeMib,proto3" json:"sizeMib,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResizeRequest) Reset()         { *m = ResizeRequest{} }
+func (m *ResizeRequest) String() string { return proto.CompactTextString(m) }
+func (*ResizeRequest) ProtoMessage()    {}
+func (*ResizeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{24}
+}
+
+func (m *ResizeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResizeRequest.Unmarshal(m, b)
+}
+func (m *ResizeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResizeRequest.Marshal(b, m, deterministic)
+}
+func (m *ResizeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResizeRequest.Merge(m, src)
+}
+func (m *ResizeRequest) XXX_Size() int {
+	return xxx_messageInfo_ResizeRequest.Size(m)
+}
+func (m *ResizeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResizeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResizeRequest proto.InternalMessageInfo
+
+func (m *ResizeRequest) GetVolume() *Volume {
+	if m != nil {
+		return m.Volume
+	}
+	return nil
+}
+
+func (m *ResizeRequest) GetSizeMib() int64 {
+	if m != nil {
+		return m.SizeMib
+	}
+	return 0
+}
+
+type VolumeSnapshot struct {
+	Volume               *Volume  `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VolumeSnapshot) Reset()         { *m = VolumeSnapshot{} }
+func (m *VolumeSnapshot) String() string { return proto.CompactTextString(m) }
+func (*VolumeSnapshot) ProtoMessage()    {}
+func (*VolumeSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{25}
+}
+
+func (m *VolumeSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VolumeSnapshot.Unmarshal(m, b)
+}
+func (m *VolumeSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VolumeSnapshot.Marshal(b, m, deterministic)
+}
+func (m *VolumeSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VolumeSnapshot.Merge(m, src)
+}
+func (m *VolumeSnapshot) XXX_Size() int {
+	return xxx_messageInfo_VolumeSnapshot.Size(m)
+}
+func (m *VolumeSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_VolumeSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VolumeSnapshot proto.InternalMessageInfo
+
+func (m *VolumeSnapshot) GetVolume() *Volume {
+	if m != nil {
+		return m.Volume
+	}
+	return nil
+}
+
+func (m *VolumeSnapshot) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type CloneRequest struct {
+	Snapshot *VolumeSnapshot `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	// the volume to create, it uses the backend of the snapshot's volume
+	Target               *Volume  `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CloneRequest) Reset()         { *m = CloneRequest{} }
+func (m *CloneRequest) String() string { return proto.CompactTextString(m) }
+func (*CloneRequest) ProtoMessage()    {}
+func (*CloneRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{26}
+}
+
+func (m *CloneRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CloneRequest.Unmarshal(m, b)
+}
+func (m *CloneRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CloneRequest.Marshal(b, m, deterministic)
+}
+func (m *CloneRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CloneRequest.Merge(m, src)
+}
+func (m *CloneRequest) XXX_Size() int {
+	return xxx_messageInfo_CloneRequest.Size(m)
+}
+func (m *CloneRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CloneRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CloneRequest proto.InternalMessageInfo
+
+func (m *CloneRequest) GetSnapshot() *VolumeSnapshot {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+func (m *CloneRequest) GetTarget() *Volume {
+	if m != nil {
+		return m.Target
+	}
+	return nil
+}
+
+type GuestCommand struct {
+	VmID                 *UUID    `protobuf:"bytes,1,opt,name=vmID,proto3" json:"vmID,omitempty"`
+	Command              string   `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Args                 []string `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	TimeoutSeconds       int64    `protobuf:"varint,4,opt,name=timeoutSeconds,proto3" json:"timeoutSeconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GuestCommand) Reset()         { *m = GuestCommand{} }
+func (m *GuestCommand) String() string { return proto.CompactTextString(m) }
+func (*GuestCommand) ProtoMessage()    {}
+func (*GuestCommand) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{27}
+}
+
+func (m *GuestCommand) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GuestCommand.Unmarshal(m, b)
+}
+func (m *GuestCommand) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GuestCommand.Marshal(b, m, deterministic)
+}
+func (m *GuestCommand) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GuestCommand.Merge(m, src)
+}
+func (m *GuestCommand) XXX_Size() int {
+	return xxx_messageInfo_GuestCommand.Size(m)
+}
+func (m *GuestCommand) XXX_DiscardUnknown() {
+	xxx_messageInfo_GuestCommand.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GuestCommand proto.InternalMessageInfo
+
+func (m *GuestCommand) GetVmID() *UUID {
+	if m != nil {
+		return m.VmID
+	}
+	return nil
+}
+
+func (m *GuestCommand) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *GuestCommand) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *GuestCommand) GetTimeoutSeconds() int64 {
+	if m != nil {
+		return m.TimeoutSeconds
+	}
+	return 0
+}
+
+type GuestCommandResult struct {
+	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	ExitCode             int32    `protobuf:"varint,2,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	Stdout               string   `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr               string   `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GuestCommandResult) Reset()         { *m = GuestCommandResult{} }
+func (m *GuestCommandResult) String() string { return proto.CompactTextString(m) }
+func (*GuestCommandResult) ProtoMessage()    {}
+func (*GuestCommandResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{28}
+}
+
+func (m *GuestCommandResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GuestCommandResult.Unmarshal(m, b)
+}
+func (m *GuestCommandResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GuestCommandResult.Marshal(b, m, deterministic)
+}
+func (m *GuestCommandResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GuestCommandResult.Merge(m, src)
+}
+func (m *GuestCommandResult) XXX_Size() int {
+	return xxx_messageInfo_GuestCommandResult.Size(m)
+}
+func (m *GuestCommandResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_GuestCommandResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GuestCommandResult proto.InternalMessageInfo
+
+func (m *GuestCommandResult) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+func (m *GuestCommandResult) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+func (m *GuestCommandResult) GetStdout() string {
+	if m != nil {
+		return m.Stdout
+	}
+	return ""
+}
+
+func (m *GuestCommandResult) GetStderr() string {
+	if m != nil {
+		return m.Stderr
+	}
+	return ""
+}
+
+type RootfsRequest struct {
+	Image string `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// the image is sized for the unpacked image if 0
+	SizeMib int64  `protobuf:"varint,2,opt,name=sizeMib,proto3" json:"sizeMib,omitempty"`
+	FsType  string `protobuf:"bytes,3,opt,name=fsType,proto3" json:"fsType,omitempty"`
+	// path of an init binary on the node, a minimal init is used if empty
+	Init        string               `protobuf:"bytes,4,opt,name=init,proto3" json:"init,omitempty"`
+	Credentials *RegistryCredentials `protobuf:"bytes,5,opt,name=credentials,proto3" json:"credentials,omitempty"`
+	// written after the image is unpacked and the init is installed
+	Files                []*FileInjection `protobuf:"bytes,6,rep,name=files,proto3" json:"files,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *RootfsRequest) Reset()         { *m = RootfsRequest{} }
+func (m *RootfsRequest) String() string { return proto.CompactTextString(m) }
+func (*RootfsRequest) ProtoMessage()    {}
+func (*RootfsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{29}
+}
+
+func (m *RootfsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RootfsRequest.Unmarshal(m, b)
+}
+func (m *RootfsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RootfsRequest.Marshal(b, m, deterministic)
+}
+func (m *RootfsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RootfsRequest.Merge(m, src)
+}
+func (m *RootfsRequest) XXX_Size() int {
+	return xxx_messageInfo_RootfsRequest.Size(m)
+}
+func (m *RootfsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RootfsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RootfsRequest proto.InternalMessageInfo
+
+func (m *RootfsRequest) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *RootfsRequest) GetSizeMib() int64 {
+	if m != nil {
+		return m.SizeMib
+	}
+	return 0
+}
+
+func (m *RootfsRequest) GetFsType() string {
+	if m != nil {
+		return m.FsType
+	}
+	return ""
+}
+
+func (m *RootfsRequest) GetInit() string {
+	if m != nil {
+		return m.Init
+	}
+	return ""
+}
+
+func (m *RootfsRequest) GetCredentials() *RegistryCredentials {
+	if m != nil {
+		return m.Credentials
+	}
+	return nil
+}
+
+func (m *RootfsRequest) GetFiles() []*FileInjection {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+type RootfsResponse struct {
+	Status               Status   `protobuf:"varint,1,opt,name=status,proto3,enum=node.Status" json:"status,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RootfsResponse) Reset()         { *m = RootfsResponse{} }
+func (m *RootfsResponse) String() string { return proto.CompactTextString(m) }
+func (*RootfsResponse) ProtoMessage()    {}
+func (*RootfsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c843d59d2d938e7, []int{30}
+}
+
+func (m *RootfsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RootfsResponse.Unmarshal(m, b)
+}
+func (m *RootfsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RootfsResponse.Marshal(b, m, deterministic)
+}
+func (m *RootfsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RootfsResponse.Merge(m, src)
+}
+func (m *RootfsResponse) XXX_Size() int {
+	return xxx_messageInfo_RootfsResponse.Size(m)
+}
+func (m *RootfsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RootfsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RootfsResponse proto.InternalMessageInfo
+
+func (m *RootfsResponse) GetStatus() Status {
+	if m != nil {
+		return m.Status
+	}
+	return Status_SUCCESS
+}
+
+func (m *RootfsResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *RootfsResponse) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("node.RestartPolicy", RestartPolicy_name, RestartPolicy_value)
+	proto.RegisterEnum("node.ProbeType", ProbeType_name, ProbeType_value)
+	proto.RegisterEnum("node.Status", Status_name, Status_value)
+	proto.RegisterEnum("node.VmState", VmState_name, VmState_value)
+	proto.RegisterEnum("node.OperationState", OperationState_name, OperationState_value)
+	proto.RegisterEnum("node.EraseMode", EraseMode_name, EraseMode_value)
+	proto.RegisterEnum("node.ConnectMode", ConnectMode_name, ConnectMode_value)
+	proto.RegisterType((*UUID)(nil), "node.UUID")
+	proto.RegisterType((*VmConfig)(nil), "node.VmConfig")
+	proto.RegisterType((*Firewall)(nil), "node.Firewall")
+	proto.RegisterType((*FirewallRule)(nil), "node.FirewallRule")
+	proto.RegisterType((*RootfsOverlay)(nil), "node.RootfsOverlay")
+	proto.RegisterType((*RestartConfig)(nil), "node.RestartConfig")
+	proto.RegisterType((*ReadinessProbe)(nil), "node.ReadinessProbe")
+	proto.RegisterType((*BalloonConfig)(nil), "node.BalloonConfig")
+	proto.RegisterType((*BalloonTarget)(nil), "node.BalloonTarget")
+	proto.RegisterType((*BalloonStats)(nil), "node.BalloonStats")
+	proto.RegisterType((*Response)(nil), "node.Response")
+	proto.RegisterType((*VmResponse)(nil), "node.VmResponse")
+	proto.RegisterType((*VmInfo)(nil), "node.VmInfo")
+	proto.RegisterType((*VmList)(nil), "node.VmList")
+	proto.RegisterType((*RegistryCredentials)(nil), "node.RegistryCredentials")
+	proto.RegisterType((*ImageName)(nil), "node.ImageName")
+	proto.RegisterType((*LayerProgress)(nil), "node.LayerProgress")
+	proto.RegisterType((*Operation)(nil), "node.Operation")
+	proto.RegisterType((*ImageInfo)(nil), "node.ImageInfo")
+	proto.RegisterType((*ImageList)(nil), "node.ImageList")
+	proto.RegisterType((*DriveResponse)(nil), "node.DriveResponse")
+	proto.RegisterType((*ConnectResponse)(nil), "node.ConnectResponse")
+	proto.RegisterType((*Volume)(nil), "node.Volume")
+	proto.RegisterType((*FileInjection)(nil), "node.FileInjection")
+	proto.RegisterType((*ResizeRequest)(nil), "node.ResizeRequest")
+	proto.RegisterType((*VolumeSnapshot)(nil), "node.VolumeSnapshot")
+	proto.RegisterType((*CloneRequest)(nil), "node.CloneRequest")
+	proto.RegisterType((*GuestCommand)(nil), "node.GuestCommand")
+	proto.RegisterType((*GuestCommandResult)(nil), "node.GuestCommandResult")
+	proto.RegisterType((*RootfsRequest)(nil), "node.RootfsRequest")
+	proto.RegisterType((*RootfsResponse)(nil), "node.RootfsResponse")
+}
+
+func init() { proto.RegisterFile("node.proto", fileDescriptor_0c843d59d2d938e7) }
+
+var fileDescriptor_0c843d59d2d938e7 = []byte{
+	// 2428 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x18, 0xc9, 0x72, 0xdb, 0xc8,
+	0x55, 0x24, 0x48, 0x8a, 0x7c, 0x5c, 0x84, 0xf4, 0x68, 0x1c, 0x46, 0x71, 0xb9, 0x14, 0xcc, 0xa6,
+	0x51, 0xe2, 0x65, 0xe4, 0x8c, 0xb3, 0x4f, 0x15, 0x4d, 0x52, 0x36, 0x63, 0x71, 0xa9, 0x26, 0x25,
+	0x97, 0xa7, 0x52, 0x51, 0x20, 0xa2, 0x49, 0x63, 0x04, 0xa0, 0x69, 0x2c, 0xb2, 0xe5, 0x53, 0x0e,
+	0xa9, 0xcc, 0x27, 0xe4, 0x96, 0x1f, 0xc8, 0x29, 0x97, 0x54, 0xe5, 0x90, 0xaf, 0xc8, 0x0f, 0xa5,
+	0x5e, 0x77, 0x03, 0x04, 0x28, 0xda, 0x92, 0x73, 0xeb, 0xb7, 0xf4, 0x7b, 0xaf, 0xdf, 0x0e, 0x00,
+	0x78, 0xdc, 0x62, 0xf7, 0x16, 0x3e, 0x0f, 0x39, 0x29, 0xe0, 0x79, 0xe7, 0xc7, 0x73, 0xce, 0xe7,
+	0x0e, 0xbb, 0x2f, 0x70, 0x67, 0xd1, 0xec, 0x3e, 0x73, 0x17, 0xe1, 0xa5, 0x64, 0x31, 0x6e, 0x43,
+	0xe1, 0xf8, 0xb8, 0xd7, 0x21, 0xdb, 0x50, 0xbc, 0x30, 0x9d, 0x88, 0x35, 0x73, 0xbb, 0xb9, 0xbd,
+	0x0a, 0x95, 0x80, 0xf1, 0xb7, 0x02, 0x94, 0x4f, 0xdc, 0x36, 0xf7, 0x66, 0xf6, 0x9c, 0xdc, 0x81,
+	0xc2, 0x85, 0xdb, 0xeb, 0x08, 0x8e, 0xea, 0x01, 0xdc, 0x13, 0x8a, 0xf0, 0x32, 0x15, 0x78, 0x72,
+	0x0b, 0x4a, 0x2e, 0x73, 0xb9, 0x7f, 0xd9, 0xcc, 0xef, 0xe6, 0xf6, 0x34, 0xaa, 0x20, 0x21, 0x7a,
+	0xba, 0x88, 0x82, 0xa6, 0x26, 0xd0, 0x12, 0x20, 0xbb, 0x50, 0x3d, 0x67, 0xbe, 0xc7, 0x9c, 0x9e,
+	0x6b, 0xce, 0x59, 0xb3, 0x20, 0xd4, 0xa6, 0x51, 0xe4, 0x73, 0x68, 0xf8, 0x9c, 0x87, 0x87, 0xb6,
+	0xc3, 0xc6, 0x97, 0x41, 0xc8, 0xdc, 0x66, 0x51, 0x30, 0xad, 0x60, 0x49, 0x13, 0x36, 0x4d, 0xcb,
+	0xf2, 0x59, 0x10, 0x34, 0x4b, 0x82, 0x21, 0x06, 0xc9, 0x5d, 0xd8, 0x3c, 0x33, 0x1d, 0x87, 0x73,
+	0xaf, 0xb9, 0x29, 0x8c, 0xfe, 0x48, 0x1a, 0xfd, 0x58, 0x22, 0xe5, 0xbb, 0x68, 0xcc, 0x83, 0x26,
+	0x31, 0xcf, 0x3c, 0x73, 0xd8, 0x49, 0xc0, 0xa7, 0xe7, 0xcd, 0xf2, 0x6e, 0x6e, 0xaf, 0x4c, 0xd3,
+	0x28, 0xb2, 0x03, 0xe5, 0x0b, 0x3c, 0xb4, 0x7b, 0x9d, 0x66, 0x65, 0x37, 0xb7, 0x57, 0xa7, 0x09,
+	0x4c, 0x7e, 0x0b, 0x0d, 0x9f, 0x99, 0x96, 0xed, 0xb1, 0x20, 0x18, 0xf9, 0xfc, 0x8c, 0x35, 0x41,
+	0xe8, 0xdc, 0x96, 0x3a, 0x69, 0x86, 0x46, 0x57, 0x78, 0xd1, 0x54, 0x9f, 0x05, 0xa1, 0xe9, 0x87,
+	0xcd, 0x6a, 0xda, 0x54, 0x2a, 0x91, 0xb1, 0xa9, 0x8a, 0x87, 0xfc, 0x0a, 0xea, 0xe8, 0x85, 0x59,
+	0x30, 0xbc, 0x60, 0xbe, 0x63, 0x5e, 0x36, 0x6b, 0x99, 0x4b, 0x69, 0x12, 0xcd, 0x72, 0xa2, 0xbb,
+	0x3c, 0x16, 0xbe, 0xe6, 0xfe, 0x79, 0xb3, 0x2e, 0xdd, 0xa5, 0x40, 0xb2, 0x0f, 0xe5, 0x99, 0xed,
+	0xb3, 0xd7, 0xa6, 0xe3, 0x34, 0x1b, 0x42, 0x5e, 0x43, 0xca, 0x3b, 0x54, 0x58, 0x9a, 0xd0, 0x0d,
+	0x0b, 0xca, 0x31, 0x96, 0xfc, 0x0c, 0x36, 0x6d, 0x6f, 0x2e, 0x02, 0x90, 0xdb, 0xd5, 0xf6, 0xaa,
+	0x07, 0x64, 0xe5, 0x5a, 0xe4, 0x30, 0x1a, 0xb3, 0x90, 0x7d, 0x28, 0x31, 0xc9, 0x9c, 0x7f, 0x27,
+	0xb3, 0xe2, 0x30, 0x7c, 0xa8, 0xa5, 0xf1, 0x84, 0x40, 0x61, 0x6a, 0x5b, 0xbe, 0x4a, 0x52, 0x71,
+	0xc6, 0x98, 0x88, 0x54, 0x9e, 0x72, 0x47, 0x24, 0x5e, 0x85, 0x26, 0x30, 0xd2, 0x66, 0x3e, 0x77,
+	0x47, 0xdc, 0x0f, 0x45, 0xf6, 0xd5, 0x69, 0x02, 0x63, 0xba, 0x86, 0x5c, 0x50, 0x0a, 0x82, 0xa2,
+	0x20, 0xa3, 0x05, 0xf5, 0x8c, 0xff, 0xc8, 0x6d, 0xa8, 0x9c, 0x99, 0x01, 0x93, 0x79, 0x2a, 0x35,
+	0x2f, 0x11, 0x68, 0xd2, 0x39, 0x63, 0x0b, 0xa1, 0xba, 0x4c, 0xc5, 0xd9, 0xf8, 0x7b, 0x0e, 0xea,
+	0x99, 0xc0, 0x91, 0x9f, 0x42, 0x69, 0xc1, 0x1d, 0x7b, 0x7a, 0x29, 0x04, 0x34, 0x56, 0xa2, 0x3b,
+	0x12, 0x24, 0xaa, 0x58, 0xc8, 0x1d, 0x00, 0xd7, 0x7c, 0x43, 0x59, 0xe8, 0xdb, 0x2c, 0x10, 0x82,
+	0x8b, 0x34, 0x85, 0x91, 0x06, 0x4d, 0xcf, 0xf9, 0x6c, 0xd6, 0x8f, 0x8b, 0x6a, 0x89, 0x20, 0x06,
+	0xd4, 0x5c, 0xf3, 0xcd, 0xe3, 0x84, 0xa1, 0x20, 0x18, 0x32, 0x38, 0xe3, 0x3f, 0x39, 0x68, 0x64,
+	0x13, 0x92, 0x7c, 0x02, 0x85, 0xf0, 0x72, 0xc1, 0x94, 0x7d, 0x5b, 0xd2, 0x3e, 0x41, 0x9a, 0x5c,
+	0x2e, 0x18, 0x15, 0x44, 0x7c, 0xec, 0x02, 0x3d, 0x26, 0x6d, 0x12, 0x67, 0x81, 0x33, 0xc3, 0x97,
+	0xc2, 0x90, 0x0a, 0x15, 0x67, 0xd1, 0x0a, 0x4c, 0xff, 0x9c, 0xf9, 0xaa, 0xae, 0x15, 0x84, 0x25,
+	0x1d, 0xda, 0x2e, 0xe3, 0x51, 0x38, 0x66, 0x53, 0xee, 0x59, 0x81, 0x28, 0x69, 0x8d, 0xae, 0x60,
+	0xd1, 0x03, 0xb6, 0x17, 0x32, 0xff, 0xc2, 0x74, 0xfa, 0xb2, 0xaa, 0x35, 0x9a, 0xc2, 0x18, 0xdf,
+	0xe7, 0xa0, 0x9e, 0x29, 0x62, 0xf4, 0x89, 0xe9, 0xf2, 0xc8, 0x0b, 0xfb, 0xf6, 0x99, 0x78, 0x83,
+	0x46, 0x97, 0x08, 0xf4, 0x89, 0xc5, 0x66, 0x8e, 0x19, 0xb2, 0xa1, 0x37, 0xe4, 0xae, 0x0a, 0x56,
+	0x06, 0x47, 0x7e, 0x0e, 0x1f, 0x07, 0xa1, 0x19, 0x06, 0x23, 0xee, 0x38, 0xb6, 0x37, 0xef, 0x29,
+	0x6d, 0x63, 0xe5, 0xe1, 0xf5, 0x44, 0xa3, 0x9f, 0x18, 0x32, 0x31, 0xfd, 0x39, 0x0b, 0xaf, 0xed,
+	0x92, 0xb7, 0xa1, 0x12, 0x0a, 0x4e, 0x34, 0x54, 0x36, 0xca, 0x25, 0xc2, 0xf8, 0x97, 0x06, 0x35,
+	0x25, 0x6f, 0x8c, 0xfa, 0xc8, 0xa7, 0x50, 0x42, 0xc5, 0x51, 0xa0, 0x02, 0x53, 0x93, 0x02, 0xc7,
+	0x02, 0x47, 0x15, 0xed, 0xfd, 0x42, 0x85, 0x6f, 0xa6, 0x61, 0x64, 0x3a, 0x48, 0x55, 0xf9, 0x92,
+	0x20, 0xb0, 0xeb, 0x49, 0xd6, 0x91, 0x39, 0x67, 0x71, 0xba, 0xa4, 0x51, 0xc8, 0x21, 0xd9, 0x25,
+	0x87, 0x0c, 0x59, 0x1a, 0x85, 0xf1, 0x0e, 0x5e, 0x9b, 0x8b, 0x9e, 0xa7, 0x62, 0xa5, 0x20, 0xec,
+	0x35, 0x78, 0x1a, 0x46, 0xa1, 0x68, 0xc0, 0x1a, 0x8d, 0x41, 0x94, 0xe9, 0x9a, 0xdf, 0x71, 0xff,
+	0xd0, 0x8c, 0x9c, 0x30, 0x10, 0xbd, 0x56, 0xa3, 0x69, 0x94, 0xe0, 0xb0, 0xbd, 0x84, 0xa3, 0xa2,
+	0x38, 0x96, 0x28, 0xcc, 0x92, 0x99, 0xcf, 0x58, 0x5f, 0x0e, 0x1d, 0x90, 0x59, 0xb2, 0xc4, 0x88,
+	0x97, 0xf1, 0xd0, 0x74, 0x14, 0x43, 0x55, 0xbd, 0x6c, 0x89, 0x22, 0x7b, 0xb0, 0x65, 0x5e, 0x98,
+	0xb6, 0x83, 0x1d, 0x5e, 0x71, 0xd5, 0x04, 0xd7, 0x2a, 0x1a, 0x75, 0x59, 0x76, 0x70, 0xde, 0x36,
+	0xa7, 0x2f, 0x59, 0x20, 0x1a, 0xa7, 0x46, 0x53, 0x18, 0xe3, 0x01, 0x94, 0x29, 0x0b, 0x16, 0xdc,
+	0x0b, 0xd8, 0xcd, 0x62, 0x66, 0x7c, 0x0b, 0x70, 0xe2, 0x7e, 0xd8, 0x1d, 0xf2, 0x39, 0x94, 0xa6,
+	0x22, 0xdf, 0x9b, 0xf9, 0x74, 0x7f, 0x8e, 0x47, 0x34, 0x55, 0x54, 0xe3, 0x9f, 0x39, 0x28, 0x9d,
+	0xb8, 0x3d, 0x6f, 0xc6, 0xaf, 0xcd, 0xc7, 0x4f, 0xa0, 0x88, 0xc2, 0x99, 0x90, 0xd8, 0x38, 0xa8,
+	0xc7, 0x12, 0x51, 0x33, 0xa3, 0x92, 0x96, 0xd2, 0xab, 0xbd, 0x4f, 0x2f, 0x7a, 0x89, 0xbd, 0xb1,
+	0x43, 0x69, 0xb5, 0xaa, 0xfd, 0x14, 0x06, 0xfb, 0xb1, 0x9a, 0x60, 0x32, 0x8d, 0x8a, 0x34, 0x81,
+	0x8d, 0xa7, 0x68, 0xf2, 0x91, 0x1d, 0xa4, 0x4b, 0x48, 0x5b, 0x6b, 0xf2, 0x1d, 0xd0, 0x2e, 0xdc,
+	0x78, 0x7c, 0xd4, 0x62, 0x53, 0xf0, 0xb5, 0x14, 0x09, 0xc6, 0x29, 0x7c, 0x44, 0xd9, 0xdc, 0x0e,
+	0x42, 0xff, 0xb2, 0xed, 0x33, 0x8b, 0x79, 0xa1, 0x6d, 0x3a, 0x42, 0x79, 0x14, 0x30, 0xdf, 0x33,
+	0xdd, 0xb8, 0x8d, 0x27, 0x30, 0xd2, 0x16, 0x66, 0x10, 0xbc, 0xe6, 0xbe, 0x95, 0x0c, 0x11, 0x05,
+	0x13, 0x1d, 0x34, 0x9f, 0xcd, 0x54, 0x7f, 0xc3, 0xa3, 0xf1, 0x07, 0xa8, 0x88, 0xe6, 0x3f, 0xc0,
+	0xab, 0x04, 0x0a, 0x29, 0x91, 0xe2, 0x4c, 0x7e, 0x03, 0xd5, 0xe9, 0x52, 0xb3, 0x0a, 0xd6, 0x8f,
+	0xe2, 0x9e, 0x7f, 0xc5, 0x34, 0x9a, 0xe6, 0x36, 0xc6, 0x50, 0x3f, 0x32, 0x2f, 0x99, 0x3f, 0xf2,
+	0xb9, 0x9c, 0x98, 0xb7, 0xa0, 0x64, 0xd9, 0x73, 0x16, 0x84, 0x4a, 0x87, 0x82, 0x50, 0x73, 0x60,
+	0xbf, 0x65, 0xaa, 0xe0, 0xc5, 0x19, 0x79, 0xf9, 0x6c, 0x16, 0xb0, 0x50, 0x15, 0xba, 0x82, 0x8c,
+	0x3f, 0x6b, 0x50, 0x19, 0x2e, 0x98, 0x6f, 0x86, 0x36, 0xf7, 0xc8, 0x0e, 0xe4, 0x6d, 0x6b, 0x4d,
+	0x4a, 0xe4, 0x6d, 0x8b, 0xec, 0x67, 0x13, 0x42, 0xad, 0x2f, 0xc9, 0xdd, 0x4c, 0x5e, 0x6c, 0x43,
+	0xd1, 0x16, 0x63, 0x51, 0x3a, 0x47, 0x02, 0x38, 0xec, 0x1c, 0x7c, 0x00, 0x66, 0x80, 0xb6, 0xdc,
+	0x4a, 0x32, 0x8f, 0xa2, 0x8a, 0x05, 0x45, 0x30, 0xdf, 0xe7, 0xbe, 0x5a, 0xee, 0x24, 0x90, 0x0c,
+	0x95, 0x52, 0x6a, 0xa8, 0xc4, 0xcf, 0xdd, 0x4c, 0x3d, 0xd7, 0x80, 0x5a, 0xe4, 0x2d, 0xcc, 0xe9,
+	0x39, 0xb3, 0xc6, 0x48, 0x93, 0x7d, 0x24, 0x83, 0xc3, 0x22, 0xf7, 0xd9, 0xab, 0xc8, 0xf6, 0x25,
+	0x8c, 0x4d, 0x50, 0x36, 0x93, 0x55, 0xb4, 0x58, 0xef, 0xb8, 0x13, 0xb9, 0xac, 0xd7, 0x11, 0xed,
+	0xa4, 0x42, 0x13, 0x18, 0x9b, 0xc9, 0xd9, 0x65, 0xc8, 0x82, 0xae, 0x6f, 0x06, 0xcc, 0x8a, 0x9b,
+	0x49, 0x0a, 0x85, 0xc9, 0x2f, 0xc0, 0x09, 0x36, 0x18, 0xd5, 0x47, 0x52, 0x18, 0xe3, 0x99, 0xca,
+	0x1a, 0x51, 0x96, 0xeb, 0xb2, 0x66, 0x19, 0xe7, 0xfc, 0xda, 0x38, 0x6b, 0xcb, 0x87, 0x1b, 0x7f,
+	0x52, 0xc2, 0x44, 0xc1, 0x7c, 0x01, 0x25, 0xe1, 0xf9, 0x78, 0xff, 0x52, 0xd3, 0x3b, 0xd1, 0x46,
+	0x15, 0x19, 0x9d, 0x1d, 0x05, 0x18, 0x2f, 0x99, 0x32, 0x12, 0x40, 0xec, 0xab, 0x88, 0x87, 0x66,
+	0xbc, 0xa0, 0x0b, 0xc0, 0xf8, 0x47, 0x0e, 0xea, 0x1d, 0xdf, 0xbe, 0x60, 0x1f, 0xd8, 0xa3, 0xd6,
+	0x65, 0xe5, 0xba, 0x1d, 0x61, 0x35, 0x74, 0x85, 0x9b, 0x85, 0xae, 0xb8, 0x36, 0x74, 0xc6, 0x33,
+	0xd8, 0x6a, 0x73, 0xcf, 0x63, 0xd3, 0xf0, 0xc3, 0xcd, 0x5d, 0x35, 0xcd, 0xf8, 0x77, 0x1e, 0x4a,
+	0x27, 0x22, 0xf0, 0x99, 0x94, 0xc8, 0xad, 0xa4, 0x04, 0x36, 0x0d, 0xce, 0x1d, 0xec, 0x02, 0x49,
+	0xd3, 0x50, 0x30, 0xce, 0x5c, 0xe1, 0xf3, 0xd1, 0x52, 0xf6, 0x12, 0x81, 0x73, 0x11, 0x17, 0x36,
+	0xe6, 0x59, 0xaa, 0x49, 0xc6, 0x20, 0x52, 0x82, 0xcc, 0x4b, 0x63, 0x90, 0x7c, 0x06, 0x05, 0x97,
+	0x5b, 0x4c, 0x94, 0x44, 0xe3, 0xe0, 0x07, 0xf2, 0x31, 0xea, 0xcd, 0x7d, 0x6e, 0x31, 0x2a, 0xc8,
+	0x98, 0x44, 0xb3, 0x00, 0x57, 0x36, 0x51, 0x27, 0x15, 0xaa, 0x20, 0x0c, 0xf2, 0x8c, 0xfb, 0x53,
+	0xa6, 0x3e, 0x6b, 0x24, 0x40, 0xbe, 0x84, 0xe2, 0xcc, 0x76, 0x18, 0x8e, 0xd7, 0x54, 0xa5, 0xe2,
+	0xc7, 0x55, 0xcf, 0xfb, 0x8e, 0x4d, 0xb1, 0xe0, 0xa9, 0xe4, 0x20, 0x9f, 0x61, 0xa1, 0x9a, 0x81,
+	0xfc, 0xac, 0x49, 0x36, 0x44, 0x91, 0xfb, 0x42, 0xbd, 0xa4, 0x1a, 0x11, 0xd4, 0x33, 0xd7, 0x13,
+	0x07, 0xe7, 0xb2, 0xa5, 0x2c, 0xde, 0x92, 0x17, 0x9b, 0xb7, 0x34, 0x5c, 0x07, 0x2d, 0xb2, 0x2d,
+	0xb5, 0xa6, 0xe3, 0x11, 0x31, 0x73, 0xdb, 0x52, 0xeb, 0x39, 0x1e, 0xd1, 0x3b, 0x53, 0xee, 0x85,
+	0xcc, 0x0b, 0x85, 0x77, 0x6a, 0x34, 0x06, 0x8d, 0xa1, 0xd8, 0xb8, 0xed, 0xb7, 0x8c, 0xb2, 0x57,
+	0x11, 0x16, 0xcd, 0xa7, 0x50, 0x92, 0x81, 0x52, 0x6d, 0x2e, 0x9e, 0x13, 0x02, 0x47, 0x15, 0x2d,
+	0xed, 0xee, 0x7c, 0xc6, 0xdd, 0xc6, 0xef, 0xa1, 0x21, 0x79, 0xc7, 0x9e, 0xb9, 0x08, 0x5e, 0xf2,
+	0x9b, 0x4a, 0x8c, 0x0b, 0x3b, 0xbf, 0x2c, 0x6c, 0x63, 0x06, 0xb5, 0xb6, 0xc3, 0xbd, 0xc4, 0xb6,
+	0x07, 0x50, 0x0e, 0x94, 0x54, 0x25, 0x6b, 0x3b, 0x2d, 0x2b, 0xd6, 0x48, 0xcb, 0x41, 0x4a, 0xb7,
+	0xdc, 0xc8, 0x9a, 0xf9, 0x75, 0xba, 0x25, 0xcd, 0xf8, 0x4b, 0x0e, 0x6a, 0x4f, 0x50, 0x43, 0x9b,
+	0xbb, 0xae, 0xe9, 0x59, 0xd7, 0x0e, 0x7f, 0xe1, 0x4f, 0xc1, 0xaa, 0xec, 0x8d, 0x41, 0x7c, 0x86,
+	0xe9, 0xcf, 0xf1, 0xf3, 0x42, 0xc3, 0x67, 0xe0, 0x79, 0xcd, 0xf6, 0x5e, 0x58, 0xb7, 0xbd, 0x1b,
+	0x7f, 0xcd, 0x01, 0x49, 0x9b, 0x41, 0x59, 0x10, 0x39, 0xe1, 0x0d, 0xeb, 0x71, 0x07, 0xca, 0xb8,
+	0x30, 0xb4, 0xe3, 0xf4, 0x28, 0xd2, 0x04, 0x16, 0x6b, 0x66, 0x68, 0xf1, 0x28, 0x54, 0x15, 0xa5,
+	0x20, 0x85, 0x67, 0x7e, 0xf2, 0xb9, 0x21, 0x21, 0xe3, 0xbf, 0xb9, 0xf8, 0x5b, 0x2e, 0xf6, 0x7c,
+	0x32, 0xb0, 0x72, 0xe9, 0x81, 0xf5, 0xce, 0x2c, 0x48, 0x55, 0x93, 0x96, 0xa9, 0x26, 0x02, 0x05,
+	0xdb, 0xb3, 0x43, 0xa5, 0x4f, 0x9c, 0x57, 0x87, 0x7e, 0xf1, 0x43, 0x86, 0xfe, 0xb2, 0x10, 0x4b,
+	0xd7, 0x15, 0xa2, 0xf1, 0x47, 0x68, 0xc4, 0x8f, 0xfa, 0xbf, 0x3a, 0x5d, 0x7e, 0xcd, 0x4c, 0x4d,
+	0x8d, 0x96, 0xfd, 0x47, 0x50, 0xcf, 0x7c, 0x97, 0x92, 0x0a, 0x14, 0x07, 0xdd, 0x93, 0x2e, 0xd5,
+	0x37, 0x48, 0x03, 0x60, 0x38, 0x38, 0x3d, 0x6c, 0xf5, 0x8e, 0x8e, 0x69, 0x57, 0xcf, 0x11, 0x80,
+	0x52, 0xeb, 0xe8, 0x79, 0xeb, 0xc5, 0x58, 0xcf, 0xef, 0x7f, 0x03, 0x95, 0xe4, 0x7b, 0x91, 0x94,
+	0xa1, 0x30, 0x18, 0x0e, 0xba, 0xfa, 0x06, 0xd9, 0x04, 0x6d, 0xd2, 0x1e, 0xe9, 0x39, 0x44, 0xf5,
+	0xda, 0xfd, 0x91, 0x9e, 0xc7, 0xd3, 0xd3, 0xc9, 0x64, 0xa4, 0x6b, 0x78, 0x7f, 0xdc, 0xa5, 0xbd,
+	0xd6, 0x91, 0x5e, 0xd8, 0xff, 0x09, 0x94, 0xd4, 0x9a, 0x58, 0x85, 0xcd, 0xf1, 0x71, 0xbb, 0xdd,
+	0x1d, 0x8f, 0xf5, 0x0d, 0x64, 0x41, 0x7d, 0xdd, 0x8e, 0x9e, 0xdb, 0x7f, 0x0e, 0x9b, 0x6a, 0x33,
+	0x45, 0x9e, 0xe3, 0xc1, 0xb3, 0xc1, 0xf0, 0xf9, 0x40, 0xdf, 0x40, 0x80, 0x1e, 0x0f, 0x06, 0xbd,
+	0xc1, 0x13, 0x69, 0xd3, 0xa8, 0x75, 0x3c, 0xee, 0x76, 0xf4, 0xbc, 0x90, 0x34, 0x19, 0x8e, 0x46,
+	0xdd, 0x8e, 0xae, 0x91, 0x1a, 0x94, 0xc7, 0x93, 0x16, 0x9d, 0x20, 0x5b, 0x01, 0x49, 0x6d, 0xda,
+	0x1a, 0x3f, 0xed, 0x76, 0xf4, 0xe2, 0x3e, 0x85, 0x46, 0x76, 0xc3, 0x41, 0xf2, 0xa8, 0x3b, 0xe8,
+	0x20, 0xef, 0x06, 0xd9, 0x82, 0x6a, 0x6f, 0x70, 0x3a, 0xa2, 0xc3, 0x27, 0x14, 0x8d, 0x12, 0x6f,
+	0xe9, 0xe0, 0xf3, 0xf2, 0xe8, 0x9c, 0x2e, 0xa5, 0x43, 0xaa, 0x6b, 0xa4, 0x0e, 0x95, 0x76, 0x6b,
+	0xd0, 0xee, 0x1e, 0xa1, 0xb1, 0x85, 0xfd, 0x47, 0x50, 0x49, 0xba, 0x23, 0xd2, 0x9e, 0x75, 0xbb,
+	0xa3, 0xd3, 0x4e, 0x6b, 0xd2, 0x92, 0x06, 0x77, 0x7a, 0xe3, 0x76, 0x8b, 0x76, 0xf4, 0x1c, 0xd2,
+	0xbe, 0xed, 0xd2, 0xe1, 0xe9, 0x61, 0xef, 0xe8, 0x48, 0xcf, 0xef, 0xf7, 0xa0, 0x9a, 0x6a, 0xeb,
+	0xe4, 0x87, 0xf0, 0xd1, 0xe1, 0x90, 0xf6, 0x5b, 0x93, 0xd3, 0xd6, 0xa0, 0x73, 0x3a, 0x1a, 0x8e,
+	0x8e, 0x8f, 0x5a, 0x93, 0xae, 0x34, 0xaa, 0x35, 0x99, 0xb4, 0xda, 0x4f, 0x4f, 0x87, 0x83, 0xa3,
+	0x17, 0x7a, 0x8e, 0xe8, 0x50, 0x53, 0x9c, 0xdd, 0xfe, 0x68, 0xf2, 0x42, 0xcf, 0x1f, 0x7c, 0x0f,
+	0x50, 0x18, 0xa0, 0x90, 0xbb, 0xb0, 0x39, 0xc6, 0x88, 0x9e, 0xf4, 0xc9, 0xca, 0xee, 0xbe, 0xa3,
+	0xc7, 0x70, 0x9c, 0x4e, 0xc6, 0x06, 0xee, 0xfb, 0xe3, 0x90, 0x2f, 0x4e, 0xfa, 0x24, 0xd5, 0x33,
+	0x76, 0x1a, 0xc9, 0x4f, 0x8b, 0x98, 0x6f, 0x0f, 0xca, 0x1d, 0xe6, 0xb0, 0x90, 0x5d, 0xcb, 0xf9,
+	0x15, 0x6c, 0xe2, 0xaa, 0x72, 0xd2, 0x0f, 0xc8, 0xad, 0x7b, 0xf2, 0x87, 0xe4, 0xbd, 0xf8, 0x87,
+	0xe4, 0xbd, 0x2e, 0xfe, 0x90, 0xdc, 0x49, 0x36, 0x79, 0x64, 0x34, 0x36, 0xc8, 0x17, 0xb0, 0x39,
+	0x32, 0xa3, 0x80, 0xdd, 0xc4, 0x0a, 0x6c, 0x31, 0xee, 0xf5, 0x9c, 0x0f, 0x01, 0xc6, 0x2c, 0x54,
+	0x1f, 0xd8, 0x24, 0xfb, 0x37, 0x50, 0x7e, 0xbf, 0xaf, 0xbd, 0xb4, 0xf5, 0x24, 0xb9, 0x24, 0xbf,
+	0xca, 0xd3, 0x5a, 0x48, 0x46, 0x8a, 0xa0, 0x1b, 0x1b, 0xe4, 0x4b, 0xa8, 0x88, 0x16, 0x38, 0xb2,
+	0xbd, 0xf9, 0x35, 0x46, 0xfd, 0x0e, 0xaa, 0xdd, 0x37, 0x6c, 0xda, 0xf3, 0xc4, 0x05, 0xa2, 0xe4,
+	0xa5, 0x1b, 0xe8, 0x4e, 0xf3, 0x2a, 0x4e, 0x36, 0x55, 0x63, 0x83, 0x7c, 0x0d, 0xd5, 0xb6, 0xcf,
+	0xcc, 0x90, 0x89, 0x65, 0x8d, 0xa4, 0x77, 0x3f, 0xdc, 0x44, 0x76, 0xd4, 0x2b, 0x33, 0xab, 0x9c,
+	0xb1, 0x41, 0x7e, 0x0d, 0xd5, 0xc7, 0x91, 0xed, 0x58, 0xb2, 0x95, 0x90, 0xcc, 0x9f, 0x43, 0xd5,
+	0x2d, 0x77, 0xb6, 0xb3, 0xc8, 0xe4, 0xee, 0x2f, 0x00, 0x30, 0x46, 0x3d, 0xb9, 0x54, 0xbe, 0x2b,
+	0x9e, 0x69, 0x4b, 0x54, 0x48, 0x1f, 0x40, 0x95, 0x32, 0x97, 0x5f, 0xa8, 0x7f, 0x67, 0x57, 0x6c,
+	0xbd, 0xea, 0x9c, 0xfb, 0x50, 0x19, 0x45, 0x8e, 0xf3, 0x0e, 0xfe, 0xad, 0x95, 0x8f, 0x13, 0x63,
+	0x83, 0xdc, 0x85, 0xda, 0x13, 0x16, 0x26, 0x98, 0x8c, 0xef, 0xd7, 0xb0, 0x7f, 0x05, 0x8d, 0xe7,
+	0x66, 0x38, 0x7d, 0x79, 0xd3, 0x0b, 0x0f, 0x72, 0xe4, 0x3e, 0x6c, 0xb5, 0x4d, 0x6f, 0xca, 0x9c,
+	0xf5, 0x77, 0xae, 0xbe, 0xe1, 0x11, 0xd4, 0x55, 0x41, 0xab, 0xa5, 0x32, 0x33, 0xbd, 0x77, 0x3e,
+	0xce, 0xac, 0x72, 0xa9, 0x7b, 0x07, 0xa0, 0x77, 0xec, 0x60, 0xfa, 0x9e, 0xab, 0x57, 0x75, 0x3d,
+	0xc0, 0xe6, 0xed, 0x30, 0x33, 0x60, 0x6b, 0x2f, 0xac, 0xf1, 0xc0, 0x2f, 0xa1, 0x11, 0x2f, 0x1c,
+	0xea, 0xca, 0xda, 0x65, 0x64, 0x8d, 0xae, 0x6f, 0x60, 0x5b, 0x55, 0x7f, 0x86, 0xf3, 0xc6, 0xf7,
+	0x1f, 0x42, 0x55, 0xac, 0x45, 0x4a, 0xad, 0x4a, 0xfc, 0xf4, 0xa6, 0xb4, 0xe6, 0xd2, 0xd7, 0x50,
+	0x93, 0x8b, 0x9e, 0xba, 0xb5, 0xfc, 0x93, 0x6a, 0xbf, 0x7d, 0xf7, 0xb5, 0xb3, 0x92, 0x48, 0xce,
+	0x87, 0xff, 0x1b, 0x00, 0x13, 0xdf, 0xd4, 0x6a, 0x1f, 0x19, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// NodeClient is the client API for Node service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type NodeClient interface {
+	StartVM(ctx context.Context, in *VmConfig, opts ...grpc.CallOption) (*VmResponse, error)
+	StopVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	// stops the VM if it runs and disconnects the volumes it booted from,
+	// StopVM keeps them connected so the VM can be started again
+	DeleteVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	ListVMs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VmList, error)
+	PauseVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	ResumeVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	SetBalloon(ctx context.Context, in *BalloonTarget, opts ...grpc.CallOption) (*Response, error)
+	GetBalloonStats(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*BalloonStats, error)
+	GuestPing(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	ExecInGuest(ctx context.Context, in *GuestCommand, opts ...grpc.CallOption) (*GuestCommandResult, error)
+	CreateDrive(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*DriveResponse, error)
+	BuildRootfs(ctx context.Context, in *RootfsRequest, opts ...grpc.CallOption) (*RootfsResponse, error)
+	ListImages(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ImageList, error)
+	RemoveImage(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*Response, error)
+	PullImage(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*Operation, error)
+	GetOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Operation, error)
+	WatchOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (Node_WatchOperationClient, error)
+	CancelOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error)
+	ConnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*ConnectResponse, error)
+	DisconnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*Response, error)
+	// disconnects the volume in the background after erasing it
+	ReleaseVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*Operation, error)
+	SnapshotVolume(ctx context.Context, in *VolumeSnapshot, opts ...grpc.CallOption) (*Response, error)
+	DeleteVolumeSnapshot(ctx context.Context, in *VolumeSnapshot, opts ...grpc.CallOption) (*Response, error)
+	CloneVolume(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*Response, error)
+	ResizeVolume(ctx context.Context, in *ResizeRequest, opts ...grpc.CallOption) (*Response, error)
+}
+
+type nodeClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewNodeClient(cc *grpc.ClientConn) NodeClient {
+	return &nodeClient{cc}
+}
+
+func (c *nodeClient) StartVM(ctx context.Context, in *VmConfig, opts ...grpc.CallOption) (*VmResponse, error) {
+	out := new(VmResponse)
+	err := c.cc.Invoke(ctx, "/node.Node/StartVM", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) StopVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/StopVM", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) DeleteVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/DeleteVM", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ListVMs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VmList, error) {
+	out := new(VmList)
+	err := c.cc.Invoke(ctx, "/node.Node/ListVMs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) PauseVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/PauseVM", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ResumeVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/ResumeVM", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) SetBalloon(ctx context.Context, in *BalloonTarget, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/SetBalloon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBalloonStats(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*BalloonStats, error) {
+	out := new(BalloonStats)
+	err := c.cc.Invoke(ctx, "/node.Node/GetBalloonStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GuestPing(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/GuestPing", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ExecInGuest(ctx context.Context, in *GuestCommand, opts ...grpc.CallOption) (*GuestCommandResult, error) {
+	out := new(GuestCommandResult)
+	err := c.cc.Invoke(ctx, "/node.Node/ExecInGuest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) CreateDrive(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*DriveResponse, error) {
+	out := new(DriveResponse)
+	err := c.cc.Invoke(ctx, "/node.Node/CreateDrive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) BuildRootfs(ctx context.Context, in *RootfsRequest, opts ...grpc.CallOption) (*RootfsResponse, error) {
+	out := new(RootfsResponse)
+	err := c.cc.Invoke(ctx, "/node.Node/BuildRootfs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ListImages(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ImageList, error) {
+	out := new(ImageList)
+	err := c.cc.Invoke(ctx, "/node.Node/ListImages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) RemoveImage(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/RemoveImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) PullImage(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*Operation, error) {
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, "/node.Node/PullImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Operation, error) {
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, "/node.Node/GetOperation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) WatchOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (Node_WatchOperationClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Node_serviceDesc.Streams[0], "/node.Node/WatchOperation", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeWatchOperationClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Node_WatchOperationClient interface {
+	Recv() (*Operation, error)
+	grpc.ClientStream
+}
+
+type nodeWatchOperationClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeWatchOperationClient) Recv() (*Operation, error) {
+	m := new(Operation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeClient) CancelOperation(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/CancelOperation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ConnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*ConnectResponse, error) {
+	out := new(ConnectResponse)
+	err := c.cc.Invoke(ctx, "/node.Node/ConnectVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) DisconnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/DisconnectVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ReleaseVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*Operation, error) {
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, "/node.Node/ReleaseVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) SnapshotVolume(ctx context.Context, in *VolumeSnapshot, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/SnapshotVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) DeleteVolumeSnapshot(ctx context.Context, in *VolumeSnapshot, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/DeleteVolumeSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) CloneVolume(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/CloneVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ResizeVolume(ctx context.Context, in *ResizeRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/node.Node/ResizeVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NodeServer is the server API for Node service.
+type NodeServer interface {
+	StartVM(context.Context, *VmConfig) (*VmResponse, error)
+	StopVM(context.Context, *UUID) (*Response, error)
+	// stops the VM if it runs and disconnects the volumes it booted from,
+	// StopVM keeps them connected so the VM can be started again
+	DeleteVM(context.Context, *UUID) (*Response, error)
+	ListVMs(context.Context, *empty.Empty) (*VmList, error)
+	PauseVM(context.Context, *UUID) (*Response, error)
+	ResumeVM(context.Context, *UUID) (*Response, error)
+	SetBalloon(context.Context, *BalloonTarget) (*Response, error)
+	GetBalloonStats(context.Context, *UUID) (*BalloonStats, error)
+	GuestPing(context.Context, *UUID) (*Response, error)
+	ExecInGuest(context.Context, *GuestCommand) (*GuestCommandResult, error)
+	CreateDrive(context.Context, *ImageName) (*DriveResponse, error)
+	BuildRootfs(context.Context, *RootfsRequest) (*RootfsResponse, error)
+	ListImages(context.Context, *empty.Empty) (*ImageList, error)
+	RemoveImage(context.Context, *ImageName) (*Response, error)
+	PullImage(context.Context, *ImageName) (*Operation, error)
+	GetOperation(context.Context, *UUID) (*Operation, error)
+	WatchOperation(*UUID, Node_WatchOperationServer) error
+	CancelOperation(context.Context, *UUID) (*Response, error)
+	ConnectVolume(context.Context, *Volume) (*ConnectResponse, error)
+	DisconnectVolume(context.Context, *Volume) (*Response, error)
+	// disconnects the volume in the background after erasing it
+	ReleaseVolume(context.Context, *Volume) (*Operation, error)
+	SnapshotVolume(context.Context, *VolumeSnapshot) (*Response, error)
+	DeleteVolumeSnapshot(context.Context, *VolumeSnapshot) (*Response, error)
+	CloneVolume(context.Context, *CloneRequest) (*Response, error)
+	ResizeVolume(context.Context, *ResizeRequest) (*Response, error)
+}
+
+// UnimplementedNodeServer can be embedded to have forward compatible implementations.
+type UnimplementedNodeServer struct {
+}
+
+func (*UnimplementedNodeServer) StartVM(ctx context.Context, req *VmConfig) (*VmResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartVM not implemented")
+}
+func (*UnimplementedNodeServer) StopVM(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopVM not implemented")
+}
+func (*UnimplementedNodeServer) DeleteVM(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVM not implemented")
+}
+func (*UnimplementedNodeServer) ListVMs(ctx context.Context, req *empty.Empty) (*VmList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVMs not implemented")
+}
+func (*UnimplementedNodeServer) PauseVM(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseVM not implemented")
+}
+func (*UnimplementedNodeServer) ResumeVM(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeVM not implemented")
+}
+func (*UnimplementedNodeServer) SetBalloon(ctx context.Context, req *BalloonTarget) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBalloon not implemented")
+}
+func (*UnimplementedNodeServer) GetBalloonStats(ctx context.Context, req *UUID) (*BalloonStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalloonStats not implemented")
+}
+func (*UnimplementedNodeServer) GuestPing(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GuestPing not implemented")
+}
+func (*UnimplementedNodeServer) ExecInGuest(ctx context.Context, req *GuestCommand) (*GuestCommandResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecInGuest not implemented")
+}
+func (*UnimplementedNodeServer) CreateDrive(ctx context.Context, req *ImageName) (*DriveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDrive not implemented")
+}
+func (*UnimplementedNodeServer) BuildRootfs(ctx context.Context, req *RootfsRequest) (*RootfsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildRootfs not implemented")
+}
+func (*UnimplementedNodeServer) ListImages(ctx context.Context, req *empty.Empty) (*ImageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListImages not implemented")
+}
+func (*UnimplementedNodeServer) RemoveImage(ctx context.Context, req *ImageName) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveImage not implemented")
+}
+func (*UnimplementedNodeServer) PullImage(ctx context.Context, req *ImageName) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PullImage not implemented")
+}
+func (*UnimplementedNodeServer) GetOperation(ctx context.Context, req *UUID) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
+}
+func (*UnimplementedNodeServer) WatchOperation(req *UUID, srv Node_WatchOperationServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOperation not implemented")
+}
+func (*UnimplementedNodeServer) CancelOperation(ctx context.Context, req *UUID) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOperation not implemented")
+}
+func (*UnimplementedNodeServer) ConnectVolume(ctx context.Context, req *Volume) (*ConnectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConnectVolume not implemented")
+}
+func (*UnimplementedNodeServer) DisconnectVolume(ctx context.Context, req *Volume) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisconnectVolume not implemented")
+}
+func (*UnimplementedNodeServer) ReleaseVolume(ctx context.Context, req *Volume) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseVolume not implemented")
+}
+func (*UnimplementedNodeServer) SnapshotVolume(ctx context.Context, req *VolumeSnapshot) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnapshotVolume not implemented")
+}
+func (*UnimplementedNodeServer) DeleteVolumeSnapshot(ctx context.Context, req *VolumeSnapshot) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVolumeSnapshot not implemented")
+}
+func (*UnimplementedNodeServer) CloneVolume(ctx context.Context, req *CloneRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloneVolume not implemented")
+}
+func (*UnimplementedNodeServer) ResizeVolume(ctx context.Context, req *ResizeRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResizeVolume not implemented")
+}
+
+func RegisterNodeServer(s *grpc.Server, srv NodeServer) {
+	s.RegisterService(&_Node_serviceDesc, srv)
+}
+
+func _Node_StartVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VmConfig)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).StartVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/StartVM",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).StartVM(ctx, req.(*VmConfig))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_StopVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).StopVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/StopVM",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).StopVM(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_DeleteVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).DeleteVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/DeleteVM",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).DeleteVM(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_ListVMs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ListVMs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/ListVMs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ListVMs(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_PauseVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).PauseVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/PauseVM",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).PauseVM(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_ResumeVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ResumeVM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/ResumeVM",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ResumeVM(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_SetBalloon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalloonTarget)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).SetBalloon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/SetBalloon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).SetBalloon(ctx, req.(*BalloonTarget))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBalloonStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBalloonStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/GetBalloonStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBalloonStats(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type nodeClient struct {
-	cc *grpc.ClientConn
+func _Node_GuestPing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GuestPing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/GuestPing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GuestPing(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func NewNodeClient(cc *grpc.ClientConn) NodeClient {
-	return &nodeClient{cc}
+func _Node_ExecInGuest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GuestCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ExecInGuest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/ExecInGuest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ExecInGuest(ctx, req.(*GuestCommand))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *nodeClient) StartVM(ctx context.Context, in *VmConfig, opts ...grpc.CallOption) (*VmResponse, error) {
-	out := new(VmResponse)
-	err := c.cc.Invoke(ctx, "/node.Node/StartVM", in, out, opts...)
-	if err != nil {
+func _Node_CreateDrive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImageName)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(NodeServer).CreateDrive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/CreateDrive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).CreateDrive(ctx, req.(*ImageName))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *nodeClient) StopVM(ctx context.Context, in *UUID, opts ...grpc.CallOption) (*Response, error) {
-	out := new(Response)
-	err := c.cc.Invoke(ctx, "/node.Node/StopVM", in, out, opts...)
-	if err != nil {
+func _Node_BuildRootfs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RootfsRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(NodeServer).BuildRootfs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/BuildRootfs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).BuildRootfs(ctx, req.(*RootfsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *nodeClient) ListVMs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VmList, error) {
-	out := new(VmList)
-	err := c.cc.Invoke(ctx, "/node.Node/ListVMs", in, out, opts...)
-	if err != nil {
+func _Node_ListImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(NodeServer).ListImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/ListImages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ListImages(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *nodeClient) CreateDrive(ctx context.Context, in *ImageName, opts ...grpc.CallOption) (*DriveResponse, error) {
-	out := new(DriveResponse)
-	err := c.cc.Invoke(ctx, "/node.Node/CreateDrive", in, out, opts...)
-	if err != nil {
+func _Node_RemoveImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImageName)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(NodeServer).RemoveImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/RemoveImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).RemoveImage(ctx, req.(*ImageName))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *nodeClient) ConnectVolume(ctx context.Context, in *Volume, opts ...grpc.CallOption) (*ConnectResponse, error) {
-	out := new(ConnectResponse)
-	err := c.cc.Invoke(ctx, "/node.Node/ConnectVolume", in, out, opts...)
-	if err != nil {
+func _Node_PullImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImageName)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(NodeServer).PullImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/PullImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).PullImage(ctx, req.(*ImageName))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// NodeServer is the server API for Node service.
-type NodeServer interface {
-	StartVM(context.Context, *VmConfig) (*VmResponse, error)
-	StopVM(context.Context, *UUID) (*Response, error)
-	ListVMs(context.Context, *empty.Empty) (*VmList, error)
-	CreateDrive(context.Context, *ImageName) (*DriveResponse, error)
-	ConnectVolume(context.Context, *Volume) (*ConnectResponse, error)
+func _Node_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/GetOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetOperation(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedNodeServer can be embedded to have forward compatible implementations.
-type UnimplementedNodeServer struct {
+func _Node_WatchOperation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UUID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServer).WatchOperation(m, &nodeWatchOperationServer{stream})
 }
 
-func (*UnimplementedNodeServer) StartVM(ctx context.Context, req *VmConfig) (*VmResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method StartVM not implemented")
+type Node_WatchOperationServer interface {
+	Send(*Operation) error
+	grpc.ServerStream
 }
-func (*UnimplementedNodeServer) StopVM(ctx context.Context, req *UUID) (*Response, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method StopVM not implemented")
+
+type nodeWatchOperationServer struct {
+	grpc.ServerStream
 }
-func (*UnimplementedNodeServer) ListVMs(ctx context.Context, req *empty.Empty) (*VmList, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListVMs not implemented")
+
+func (x *nodeWatchOperationServer) Send(m *Operation) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (*UnimplementedNodeServer) CreateDrive(ctx context.Context, req *ImageName) (*DriveResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateDrive not implemented")
+
+func _Node_CancelOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UUID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).CancelOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/CancelOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).CancelOperation(ctx, req.(*UUID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (*UnimplementedNodeServer) ConnectVolume(ctx context.Context, req *Volume) (*ConnectResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ConnectVolume not implemented")
+
+func _Node_ConnectVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Volume)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ConnectVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/ConnectVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ConnectVolume(ctx, req.(*Volume))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterNodeServer(s *grpc.Server, srv NodeServer) {
-	s.RegisterService(&_Node_serviceDesc, srv)
+func _Node_DisconnectVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Volume)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).DisconnectVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/node.Node/DisconnectVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).DisconnectVolume(ctx, req.(*Volume))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Node_StartVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VmConfig)
+func _Node_ReleaseVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Volume)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NodeServer).StartVM(ctx, in)
+		return srv.(NodeServer).ReleaseVolume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/node.Node/StartVM",
+		FullMethod: "/node.Node/ReleaseVolume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NodeServer).StartVM(ctx, req.(*VmConfig))
+		return srv.(NodeServer).ReleaseVolume(ctx, req.(*Volume))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Node_StopVM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UUID)
+func _Node_SnapshotVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeSnapshot)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NodeServer).StopVM(ctx, in)
+		return srv.(NodeServer).SnapshotVolume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/node.Node/StopVM",
+		FullMethod: "/node.Node/SnapshotVolume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NodeServer).StopVM(ctx, req.(*UUID))
+		return srv.(NodeServer).SnapshotVolume(ctx, req.(*VolumeSnapshot))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Node_ListVMs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _Node_DeleteVolumeSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeSnapshot)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NodeServer).ListVMs(ctx, in)
+		return srv.(NodeServer).DeleteVolumeSnapshot(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/node.Node/ListVMs",
+		FullMethod: "/node.Node/DeleteVolumeSnapshot",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NodeServer).ListVMs(ctx, req.(*empty.Empty))
+		return srv.(NodeServer).DeleteVolumeSnapshot(ctx, req.(*VolumeSnapshot))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Node_CreateDrive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ImageName)
+func _Node_CloneVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NodeServer).CreateDrive(ctx, in)
+		return srv.(NodeServer).CloneVolume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/node.Node/CreateDrive",
+		FullMethod: "/node.Node/CloneVolume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NodeServer).CreateDrive(ctx, req.(*ImageName))
+		return srv.(NodeServer).CloneVolume(ctx, req.(*CloneRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Node_ConnectVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Volume)
+func _Node_ResizeVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(NodeServer).ConnectVolume(ctx, in)
+		return srv.(NodeServer).ResizeVolume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/node.Node/ConnectVolume",
+		FullMethod: "/node.Node/ResizeVolume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(NodeServer).ConnectVolume(ctx, req.(*Volume))
+		return srv.(NodeServer).ResizeVolume(ctx, req.(*ResizeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -748,19 +3377,101 @@ var _Node_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopVM",
 			Handler:    _Node_StopVM_Handler,
 		},
+		{
+			MethodName: "DeleteVM",
+			Handler:    _Node_DeleteVM_Handler,
+		},
 		{
 			MethodName: "ListVMs",
 			Handler:    _Node_ListVMs_Handler,
 		},
+		{
+			MethodName: "PauseVM",
+			Handler:    _Node_PauseVM_Handler,
+		},
+		{
+			MethodName: "ResumeVM",
+			Handler:    _Node_ResumeVM_Handler,
+		},
+		{
+			MethodName: "SetBalloon",
+			Handler:    _Node_SetBalloon_Handler,
+		},
+		{
+			MethodName: "GetBalloonStats",
+			Handler:    _Node_GetBalloonStats_Handler,
+		},
+		{
+			MethodName: "GuestPing",
+			Handler:    _Node_GuestPing_Handler,
+		},
+		{
+			MethodName: "ExecInGuest",
+			Handler:    _Node_ExecInGuest_Handler,
+		},
 		{
 			MethodName: "CreateDrive",
 			Handler:    _Node_CreateDrive_Handler,
 		},
+		{
+			MethodName: "BuildRootfs",
+			Handler:    _Node_BuildRootfs_Handler,
+		},
+		{
+			MethodName: "ListImages",
+			Handler:    _Node_ListImages_Handler,
+		},
+		{
+			MethodName: "RemoveImage",
+			Handler:    _Node_RemoveImage_Handler,
+		},
+		{
+			MethodName: "PullImage",
+			Handler:    _Node_PullImage_Handler,
+		},
+		{
+			MethodName: "GetOperation",
+			Handler:    _Node_GetOperation_Handler,
+		},
+		{
+			MethodName: "CancelOperation",
+			Handler:    _Node_CancelOperation_Handler,
+		},
 		{
 			MethodName: "ConnectVolume",
 			Handler:    _Node_ConnectVolume_Handler,
 		},
+		{
+			MethodName: "DisconnectVolume",
+			Handler:    _Node_DisconnectVolume_Handler,
+		},
+		{
+			MethodName: "ReleaseVolume",
+			Handler:    _Node_ReleaseVolume_Handler,
+		},
+		{
+			MethodName: "SnapshotVolume",
+			Handler:    _Node_SnapshotVolume_Handler,
+		},
+		{
+			MethodName: "DeleteVolumeSnapshot",
+			Handler:    _Node_DeleteVolumeSnapshot_Handler,
+		},
+		{
+			MethodName: "CloneVolume",
+			Handler:    _Node_CloneVolume_Handler,
+		},
+		{
+			MethodName: "ResizeVolume",
+			Handler:    _Node_ResizeVolume_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOperation",
+			Handler:       _Node_WatchOperation_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "node.proto",
 }
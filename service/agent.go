@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// agentVsockPort is the vsock port the in-guest agent listens on
+	agentVsockPort = 1024
+
+	// CIDs 0-2 are reserved for the hypervisor and the host
+	firstGuestCID = 3
+
+	agentTimeout = 10 * time.Second
+)
+
+type agentRequest struct {
+	Type    string   `json:"type"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+type agentResponse struct {
+	Error    string `json:"error,omitempty"`
+	ExitCode int32  `json:"exitCode"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// guestAgent talks to the agent running inside the guest through the unix
+// socket firecracker proxies vsock connections on. Every request uses its
+// own connection, the request and the response are single lines of JSON
+type guestAgent struct {
+	udsPath string
+	port    uint32
+}
+
+func newGuestAgent(udsPath string) *guestAgent {
+	return &guestAgent{
+		udsPath: udsPath,
+		port:    agentVsockPort,
+	}
+}
+
+// dial connects to the guest port using firecracker's host initiated
+// connection handshake: "CONNECT <port>\n" answered by "OK <host port>\n"
+func (a *guestAgent) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", a.udsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	_, err = fmt.Fprintf(conn, "CONNECT %d\n", a.port)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("Failed to connect to guest port %d: %s", a.port, err)
+	}
+
+	if !strings.HasPrefix(line, "OK ") {
+		conn.Close()
+		return nil, nil, fmt.Errorf("Unexpected vsock handshake reply %q", strings.TrimSpace(line))
+	}
+
+	return conn, reader, nil
+}
+
+func (a *guestAgent) call(ctx context.Context, req *agentRequest) (*agentResponse, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, agentTimeout)
+		defer cancel()
+	}
+
+	conn, reader, err := a.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read agent response: %s", err)
+	}
+
+	resp := new(agentResponse)
+	err = json.Unmarshal(line, resp)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid agent response %q: %s", line, err)
+	}
+
+	if resp.Error != "" {
+		return resp, fmt.Errorf("Agent failed to handle %s: %s", req.Type, resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (a *guestAgent) ping(ctx context.Context) error {
+	_, err := a.call(ctx, &agentRequest{Type: "ping"})
+	return err
+}
+
+func (a *guestAgent) exec(ctx context.Context, command string, args []string) (*agentResponse, error) {
+	return a.call(ctx, &agentRequest{
+		Type:    "exec",
+		Command: command,
+		Args:    args,
+	})
+}
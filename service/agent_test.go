@@ -0,0 +1,98 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeVsockProxy emulates firecracker's vsock unix socket with an agent
+// behind it that answers every request with resp
+func fakeVsockProxy(t *testing.T, resp *agentResponse) (string, <-chan agentRequest, func()) {
+	dir, err := ioutil.TempDir("", "vsock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "v.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	reqs := make(chan agentRequest, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		if line, _ := reader.ReadString('\n'); line != "CONNECT 1024\n" {
+			conn.Write([]byte("ERR\n"))
+			return
+		}
+		conn.Write([]byte("OK 1073741824\n"))
+
+		var req agentRequest
+		line, _ := reader.ReadBytes('\n')
+		json.Unmarshal(line, &req)
+		reqs <- req
+		json.NewEncoder(conn).Encode(resp)
+	}()
+
+	return path, reqs, func() {
+		lis.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGuestAgentPing(t *testing.T) {
+	path, reqs, cleanup := fakeVsockProxy(t, &agentResponse{})
+	defer cleanup()
+
+	err := newGuestAgent(path).ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req := <-reqs; req.Type != "ping" {
+		t.Errorf("\n\tGOT: %s \n\tEXPECTED: ping", req.Type)
+	}
+}
+
+func TestGuestAgentExec(t *testing.T) {
+	path, reqs, cleanup := fakeVsockProxy(t, &agentResponse{ExitCode: 2, Stdout: "out"})
+	defer cleanup()
+
+	resp, err := newGuestAgent(path).exec(context.Background(), "ls", []string{"/nope"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-reqs
+	if req.Type != "exec" || req.Command != "ls" || len(req.Args) != 1 {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	if resp.ExitCode != 2 || resp.Stdout != "out" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestGuestAgentError(t *testing.T) {
+	path, _, cleanup := fakeVsockProxy(t, &agentResponse{Error: "boom"})
+	defer cleanup()
+
+	err := newGuestAgent(path).ping(context.Background())
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
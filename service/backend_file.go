@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// fileBackend stores volumes as sparse files in a local directory, the
+// files are handed to firecracker as they are
+type fileBackend struct {
+	mkfs
+	log *log.Logger
+	dir string
+}
+
+func (f *fileBackend) path(vol *node.Volume) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%s.img", volumeName(vol)))
+}
+
+func (f *fileBackend) Attach(vol *node.Volume) (string, bool, error) {
+	path := f.path(vol)
+	_, err := os.Stat(path)
+	if err == nil {
+		return path, false, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", false, err
+	}
+
+	if vol.GetSizeMib() <= 0 {
+		return "", false, fmt.Errorf("Volume %s does not exist and no size was given", path)
+	}
+
+	err = os.MkdirAll(f.dir, 0755)
+	if err != nil {
+		return "", false, err
+	}
+
+	f.log.Infof("Creating sparse file %s of %d MiB", path, vol.GetSizeMib())
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	err = file.Truncate(vol.GetSizeMib() * mib)
+	if err != nil {
+		os.Remove(path)
+		return "", false, err
+	}
+
+	return path, true, nil
+}
+
+func (f *fileBackend) Detach(vol *node.Volume, device string) error {
+	return nil
+}
+
+func (f *fileBackend) Delete(vol *node.Volume) error {
+	f.log.Infof("Removing %s", f.path(vol))
+	return os.Remove(f.path(vol))
+}
+
+func (f *fileBackend) Resize(vol *node.Volume, sizeMib int64) error {
+	stat, err := f.Stat(vol)
+	if err != nil {
+		return err
+	}
+
+	if sizeMib*mib < stat.SizeBytes {
+		return fmt.Errorf("Shrinking %s is not supported", f.path(vol))
+	}
+
+	f.log.Infof("Resizing %s to %d MiB", f.path(vol), sizeMib)
+	return os.Truncate(f.path(vol), sizeMib*mib)
+}
+
+func (f *fileBackend) Stat(vol *node.Volume) (*VolumeStat, error) {
+	info, err := os.Stat(f.path(vol))
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &VolumeStat{
+		SizeBytes: info.Size(),
+	}
+	if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+		stat.AllocatedBytes = sys.Blocks * 512
+	}
+
+	return stat, nil
+}
+
+func (f *fileBackend) snapshotPath(vol *node.Volume, snapshot string) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%s-snap-%s.img", volumeName(vol), snapshot))
+}
+
+// Snapshot reflinks the volume's file, the directory has to be on a
+// filesystem supporting reflinks like XFS or btrfs
+func (f *fileBackend) Snapshot(vol *node.Volume, snapshot string) error {
+	f.log.Infof("Creating snapshot %s", f.snapshotPath(vol, snapshot))
+	return reflink(f.path(vol), f.snapshotPath(vol, snapshot))
+}
+
+func (f *fileBackend) DeleteSnapshot(vol *node.Volume, snapshot string) error {
+	f.log.Infof("Removing snapshot %s", f.snapshotPath(vol, snapshot))
+	return os.Remove(f.snapshotPath(vol, snapshot))
+}
+
+func (f *fileBackend) Clone(vol *node.Volume, snapshot string, target *node.Volume) error {
+	f.log.Infof("Cloning %s to %s", f.snapshotPath(vol, snapshot), f.path(target))
+	return reflink(f.snapshotPath(vol, snapshot), f.path(target))
+}
+
+// reflink clones src to dst sharing its blocks, dst must not exist
+func reflink(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	_, err := util.ExecuteCommand("cp", "--reflink=always", src, dst)
+	if err != nil {
+		os.Remove(dst)
+	}
+
+	return err
+}
+
+// loopBackend exposes the files of the file backend as loop devices, for
+// tools that need a real block device
+type loopBackend struct {
+	*fileBackend
+}
+
+// loopDevice returns the loop device the volume is attached to, if any
+func (l *loopBackend) loopDevice(vol *node.Volume) (string, error) {
+	out, err := util.ExecuteCommand("losetup", "-j", l.path(vol))
+	if err != nil || out == "" {
+		return "", err
+	}
+
+	// output looks like "/dev/loop0: [2049]:1234 (/var/volumes/volume-1.img)"
+	return strings.SplitN(out, ":", 2)[0], nil
+}
+
+func (l *loopBackend) Attach(vol *node.Volume) (string, bool, error) {
+	path, created, err := l.fileBackend.Attach(vol)
+	if err != nil {
+		return "", false, err
+	}
+
+	device, err := l.loopDevice(vol)
+	if err == nil && device == "" {
+		l.log.Infof("Attaching %s to a loop device", path)
+		device, err = util.ExecuteCommand("losetup", "--find", "--show", path)
+	}
+
+	if err != nil {
+		if created {
+			os.Remove(path)
+		}
+		return "", false, err
+	}
+
+	return device, created, nil
+}
+
+func (l *loopBackend) Detach(vol *node.Volume, device string) error {
+	l.log.Infof("Detaching loop device %s", device)
+	_, err := util.ExecuteCommand("losetup", "-d", device)
+	return err
+}
+
+func (l *loopBackend) Resize(vol *node.Volume, sizeMib int64) error {
+	err := l.fileBackend.Resize(vol, sizeMib)
+	if err != nil {
+		return err
+	}
+
+	device, err := l.loopDevice(vol)
+	if err != nil || device == "" {
+		return err
+	}
+
+	_, err = util.ExecuteCommand("losetup", "-c", device)
+	return err
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// lvmBackend creates volumes as thin logical volumes, the volume's pool
+// name selects the volume group if set
+type lvmBackend struct {
+	mkfs
+	log         *log.Logger
+	volumeGroup string
+	thinPool    string
+}
+
+func (l *lvmBackend) vg(vol *node.Volume) string {
+	if vol.GetPoolName() != "" {
+		return vol.GetPoolName()
+	}
+
+	return l.volumeGroup
+}
+
+func (l *lvmBackend) lv(vol *node.Volume) string {
+	return fmt.Sprintf("%s/%s", l.vg(vol), volumeName(vol))
+}
+
+func (l *lvmBackend) Attach(vol *node.Volume) (string, bool, error) {
+	if l.vg(vol) == "" {
+		return "", false, fmt.Errorf("No volume group configured for the lvm backend")
+	}
+
+	created := false
+	_, err := util.ExecuteCommand("lvs", l.lv(vol))
+	if err != nil {
+		if vol.GetSizeMib() <= 0 {
+			return "", false, fmt.Errorf("Volume %s does not exist and no size was given", l.lv(vol))
+		}
+		if l.thinPool == "" {
+			return "", false, fmt.Errorf("No thin pool configured for the lvm backend")
+		}
+
+		l.log.Infof("Creating thin volume %s of %d MiB", l.lv(vol), vol.GetSizeMib())
+		_, err = util.ExecuteCommand("lvcreate",
+			"-V", fmt.Sprintf("%dm", vol.GetSizeMib()),
+			"-T", fmt.Sprintf("%s/%s", l.vg(vol), l.thinPool),
+			"-n", volumeName(vol))
+		if err != nil {
+			return "", false, err
+		}
+		created = true
+	}
+
+	l.log.Infof("Activating %s", l.lv(vol))
+	_, err = util.ExecuteCommand("lvchange", "-ay", "-K", l.lv(vol))
+	if err != nil {
+		if created {
+			l.Delete(vol)
+		}
+		return "", false, err
+	}
+
+	return filepath.Join("/dev", l.lv(vol)), created, nil
+}
+
+func (l *lvmBackend) Detach(vol *node.Volume, device string) error {
+	l.log.Infof("Deactivating %s", l.lv(vol))
+	_, err := util.ExecuteCommand("lvchange", "-an", l.lv(vol))
+	return err
+}
+
+func (l *lvmBackend) Delete(vol *node.Volume) error {
+	l.log.Infof("Removing %s", l.lv(vol))
+	_, err := util.ExecuteCommand("lvremove", "-y", l.lv(vol))
+	return err
+}
+
+func (l *lvmBackend) Resize(vol *node.Volume, sizeMib int64) error {
+	l.log.Infof("Resizing %s to %d MiB", l.lv(vol), sizeMib)
+	_, err := util.ExecuteCommand("lvextend", "-L", fmt.Sprintf("%dm", sizeMib), l.lv(vol))
+	return err
+}
+
+func (l *lvmBackend) Stat(vol *node.Volume) (*VolumeStat, error) {
+	out, err := util.ExecuteCommand("lvs", "--noheadings", "--units", "b", "--nosuffix",
+		"-o", "lv_size", l.lv(vol))
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := strconv.ParseInt(out, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse size of %s: %s", l.lv(vol), err)
+	}
+
+	return &VolumeStat{
+		SizeBytes: size,
+	}, nil
+}
+
+func (l *lvmBackend) snapshot(vol *node.Volume, snapshot string) string {
+	return fmt.Sprintf("%s-snap-%s", l.lv(vol), snapshot)
+}
+
+// Snapshot creates a thin snapshot, it takes no space until the volume or
+// the snapshot is written
+func (l *lvmBackend) Snapshot(vol *node.Volume, snapshot string) error {
+	l.log.Infof("Creating snapshot %s", l.snapshot(vol, snapshot))
+	_, err := util.ExecuteCommand("lvcreate", "-s",
+		"-n", filepath.Base(l.snapshot(vol, snapshot)), l.lv(vol))
+	return err
+}
+
+func (l *lvmBackend) DeleteSnapshot(vol *node.Volume, snapshot string) error {
+	l.log.Infof("Removing snapshot %s", l.snapshot(vol, snapshot))
+	_, err := util.ExecuteCommand("lvremove", "-y", l.snapshot(vol, snapshot))
+	return err
+}
+
+// Clone takes a thin snapshot of the snapshot, so the clone has to be in the
+// same volume group
+func (l *lvmBackend) Clone(vol *node.Volume, snapshot string, target *node.Volume) error {
+	if l.vg(target) != l.vg(vol) {
+		return fmt.Errorf("Can't clone %s to another volume group %s",
+			l.snapshot(vol, snapshot), l.vg(target))
+	}
+
+	l.log.Infof("Cloning %s to %s", l.snapshot(vol, snapshot), l.lv(target))
+	_, err := util.ExecuteCommand("lvcreate", "-s",
+		"-n", volumeName(target), l.snapshot(vol, snapshot))
+	return err
+}
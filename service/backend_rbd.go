@@ -0,0 +1,106 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// rbdBackend maps Ceph RBD images with rbd-nbd
+type rbdBackend struct {
+	mkfs
+	log *log.Logger
+}
+
+func (r *rbdBackend) image(vol *node.Volume) string {
+	return fmt.Sprintf("%s/%s", vol.GetPoolName(), volumeName(vol))
+}
+
+// Attach maps an existing image, images are never created by the node
+func (r *rbdBackend) Attach(vol *node.Volume) (string, bool, error) {
+	command := []string{"map", r.image(vol)}
+	r.log.Infof("Executing command rbd-nbd with parameters %v", command)
+	out, err := util.ExecuteCommand("rbd-nbd", command...)
+	if err != nil {
+		r.log.Errorf("rbd-nbd failed %s", err)
+		return "", false, err
+	}
+
+	return out, false, nil
+}
+
+func (r *rbdBackend) Detach(vol *node.Volume, device string) error {
+	r.log.Infof("Unmapping %s from %s", r.image(vol), device)
+	_, err := util.ExecuteCommand("rbd-nbd", "unmap", device)
+	return err
+}
+
+func (r *rbdBackend) Delete(vol *node.Volume) error {
+	r.log.Infof("Removing %s", r.image(vol))
+	_, err := util.ExecuteCommand("rbd", "rm", r.image(vol))
+	return err
+}
+
+func (r *rbdBackend) Resize(vol *node.Volume, sizeMib int64) error {
+	r.log.Infof("Resizing %s to %d MiB", r.image(vol), sizeMib)
+	_, err := util.ExecuteCommand("rbd", "resize",
+		"--size", fmt.Sprintf("%dM", sizeMib), r.image(vol))
+	return err
+}
+
+func (r *rbdBackend) Stat(vol *node.Volume) (*VolumeStat, error) {
+	out, err := util.ExecuteCommand("rbd", "info", "--format", "json", r.image(vol))
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Size int64 `json:"size"`
+	}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse rbd info of %s: %s", r.image(vol), err)
+	}
+
+	return &VolumeStat{
+		SizeBytes: info.Size,
+	}, nil
+}
+
+func (r *rbdBackend) snapshot(vol *node.Volume, snapshot string) string {
+	return fmt.Sprintf("%s@%s", r.image(vol), snapshot)
+}
+
+// Snapshot protects the snapshot right away since rbd only clones protected
+// snapshots
+func (r *rbdBackend) Snapshot(vol *node.Volume, snapshot string) error {
+	r.log.Infof("Creating snapshot %s", r.snapshot(vol, snapshot))
+	_, err := util.ExecuteCommand("rbd", "snap", "create", r.snapshot(vol, snapshot))
+	if err != nil {
+		return err
+	}
+
+	_, err = util.ExecuteCommand("rbd", "snap", "protect", r.snapshot(vol, snapshot))
+	return err
+}
+
+func (r *rbdBackend) DeleteSnapshot(vol *node.Volume, snapshot string) error {
+	r.log.Infof("Removing snapshot %s", r.snapshot(vol, snapshot))
+	_, err := util.ExecuteCommand("rbd", "snap", "unprotect", r.snapshot(vol, snapshot))
+	if err != nil {
+		return err
+	}
+
+	_, err = util.ExecuteCommand("rbd", "snap", "rm", r.snapshot(vol, snapshot))
+	return err
+}
+
+func (r *rbdBackend) Clone(vol *node.Volume, snapshot string, target *node.Volume) error {
+	r.log.Infof("Cloning %s to %s", r.snapshot(vol, snapshot), r.image(target))
+	_, err := util.ExecuteCommand("rbd", "clone", r.snapshot(vol, snapshot), r.image(target))
+	return err
+}
@@ -0,0 +1,126 @@
+package service
+
+// Config holds the node settings read from the catapult-node config file
+type Config struct {
+	Storage StorageConfig `mapstructure:"storage"`
+	Images  ImagesConfig  `mapstructure:"images"`
+	Network NetworkConfig `mapstructure:"network"`
+}
+
+// Validate rejects settings the node can't run with
+func (c Config) Validate() error {
+	return validateOverlayMode(c.Storage.OverlayMode)
+}
+
+// StorageConfig selects and configures the volume backends
+type StorageConfig struct {
+	// Backend is used for volumes that do not ask for a specific one
+	Backend string `mapstructure:"backend"`
+	// VolumeDir holds the images of the file and loop backends
+	VolumeDir string `mapstructure:"volume_dir"`
+	// VolumeGroup and ThinPool are used by the lvm backend
+	VolumeGroup string `mapstructure:"volume_group"`
+	ThinPool    string `mapstructure:"thin_pool"`
+	// VolumeTable is where the connected volumes are recorded
+	VolumeTable string `mapstructure:"volume_table"`
+	// RootfsDir holds the images built with BuildRootfs
+	RootfsDir string `mapstructure:"rootfs_dir"`
+	// AgentBinary is installed into built images if set
+	AgentBinary string `mapstructure:"agent_binary"`
+	// OverlayMode is how VM overlays of base images are made, reflink or
+	// dm-snapshot
+	OverlayMode string `mapstructure:"overlay_mode"`
+	// OverlayDir holds the reflinked images or COW files of overlays
+	OverlayDir string `mapstructure:"overlay_dir"`
+	// SizeHeadroomPercent is added to the unpacked size of images when
+	// sizing volumes populated from them, 0 means 20%
+	SizeHeadroomPercent int64 `mapstructure:"size_headroom_percent"`
+}
+
+// ImagesConfig configures the local image store
+type ImagesConfig struct {
+	Dir string `mapstructure:"dir"`
+	// QuotaMib limits the size of the store, 0 means no limit
+	QuotaMib int64 `mapstructure:"quota_mib"`
+	// Policy is a containers/image policy.json pulls are checked against,
+	// /etc/containers/policy.json is used if it exists and Policy is unset
+	Policy string `mapstructure:"policy"`
+	// RegistriesDir holds the registries.d configuration telling where
+	// signatures of images are stored
+	RegistriesDir string `mapstructure:"registries_dir"`
+	// AuthFile is a containers auth.json with registry credentials
+	AuthFile string `mapstructure:"auth_file"`
+	// CertDir holds a host[:port] directory per registry with its CA bundle
+	// and client certificates
+	CertDir string `mapstructure:"cert_dir"`
+	// Registries configures mirrors and insecure registries
+	Registries []RegistryConfig `mapstructure:"registries"`
+	// Credentials can be referred to by name in pull requests
+	Credentials map[string]CredentialConfig `mapstructure:"credentials"`
+}
+
+// RegistryConfig configures how images under Prefix are pulled
+type RegistryConfig struct {
+	Prefix   string         `mapstructure:"prefix"`
+	Location string         `mapstructure:"location"`
+	Insecure bool           `mapstructure:"insecure"`
+	Mirrors  []MirrorConfig `mapstructure:"mirrors"`
+}
+
+// MirrorConfig is a registry mirror, mirrors are tried in order before
+// the registry itself
+type MirrorConfig struct {
+	Location string `mapstructure:"location"`
+	Insecure bool   `mapstructure:"insecure"`
+}
+
+// CredentialConfig holds registry credentials
+type CredentialConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// NetworkConfig selects and configures the network drivers
+type NetworkConfig struct {
+	// Driver connects VMs that do not ask for a specific one: bridge,
+	// routed or cni
+	Driver string       `mapstructure:"driver"`
+	Bridge BridgeConfig `mapstructure:"bridge"`
+	Routed RoutedConfig `mapstructure:"routed"`
+	CNI    CNIConfig    `mapstructure:"cni"`
+	// DisableAntiSpoofing lets VMs send traffic from other addresses than
+	// their own, the tap devices are pinned to the VM's IP and MAC address
+	// otherwise
+	DisableAntiSpoofing bool `mapstructure:"disable_anti_spoofing"`
+}
+
+// BridgeConfig configures the bridge driver and how the bridge is set up by
+// catapult-node network init
+type BridgeConfig struct {
+	// Name of the bridge, fcbridge if unset
+	Name string `mapstructure:"name"`
+	// Address is the gateway address of the VMs in CIDR notation, e.g.
+	// 172.17.0.1/16
+	Address string `mapstructure:"address"`
+	// Masquerade NATs the outbound traffic of the VMs
+	Masquerade bool `mapstructure:"masquerade"`
+	// Bootstrap sets up the bridge when the node starts
+	Bootstrap bool `mapstructure:"bootstrap"`
+}
+
+// RoutedConfig configures the routed driver
+type RoutedConfig struct {
+	// Subnet VM addresses are allocated from, its first address is the
+	// gateway of the VMs
+	Subnet string `mapstructure:"subnet"`
+}
+
+// CNIConfig configures the cni driver, the network is set up by the CNI
+// plugins of the named network
+type CNIConfig struct {
+	NetworkName string   `mapstructure:"network_name"`
+	IfName      string   `mapstructure:"if_name"`
+	BinPath     []string `mapstructure:"bin_path"`
+	ConfDir     string   `mapstructure:"conf_dir"`
+	CacheDir    string   `mapstructure:"cache_dir"`
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+)
+
+const (
+	vmStatePaused  = "Paused"
+	vmStateResumed = "Resumed"
+)
+
+// fcClient talks to the firecracker API socket directly, it is used for
+// endpoints the SDK version we depend on does not expose yet
+type fcClient struct {
+	client *http.Client
+}
+
+func newFcClient(socketPath string) *fcClient {
+	return &fcClient{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (c *fcClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, "http://localhost"+path, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s failed with status %d: %s",
+			method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
+
+// rescanDrive makes firecracker pick up the new size of a drive's backing
+// file or device and report it to the guest
+func (c *fcClient) rescanDrive(ctx context.Context, driveID string) error {
+	return c.do(ctx, http.MethodPut, "/actions", map[string]string{
+		"action_type": "BlockDeviceRescan",
+		"payload":     driveID,
+	}, nil)
+}
+
+// patchVMState changes the state of the microVM, e.g. Paused or Resumed
+func (c *fcClient) patchVMState(ctx context.Context, state string) error {
+	return c.do(ctx, http.MethodPatch, "/vm", map[string]string{"state": state}, nil)
+}
+
+type balloon struct {
+	AmountMib             int64 `json:"amount_mib"`
+	DeflateOnOom          bool  `json:"deflate_on_oom"`
+	StatsPollingIntervalS int64 `json:"stats_polling_interval_s"`
+}
+
+type balloonStatistics struct {
+	TargetPages     int64 `json:"target_pages"`
+	ActualPages     int64 `json:"actual_pages"`
+	TargetMib       int64 `json:"target_mib"`
+	ActualMib       int64 `json:"actual_mib"`
+	SwapIn          int64 `json:"swap_in"`
+	SwapOut         int64 `json:"swap_out"`
+	MajorFaults     int64 `json:"major_faults"`
+	MinorFaults     int64 `json:"minor_faults"`
+	FreeMemory      int64 `json:"free_memory"`
+	TotalMemory     int64 `json:"total_memory"`
+	AvailableMemory int64 `json:"available_memory"`
+	DiskCaches      int64 `json:"disk_caches"`
+}
+
+// putBalloon configures the balloon device, it must be called before the
+// instance is started
+func (c *fcClient) putBalloon(ctx context.Context, b *balloon) error {
+	return c.do(ctx, http.MethodPut, "/balloon", b, nil)
+}
+
+// patchBalloon updates the target size of the balloon
+func (c *fcClient) patchBalloon(ctx context.Context, amountMib int64) error {
+	return c.do(ctx, http.MethodPatch, "/balloon",
+		map[string]int64{"amount_mib": amountMib}, nil)
+}
+
+func (c *fcClient) getBalloonStats(ctx context.Context) (*balloonStatistics, error) {
+	stats := new(balloonStatistics)
+	err := c.do(ctx, http.MethodGet, "/balloon/statistics", nil, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
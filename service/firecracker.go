@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -22,16 +23,16 @@ const (
 	firecrackerBinary = "./firecracker"
 	vmDataPath        = "/var/vms"
 	vmLogs            = "fc-logs"
+
+	rootDriveID = "1"
 )
 
 //TODO better name needed
 type fc struct {
-	vmID          string
-	tapDeviceName string
-	ipAddress     string
-	macAddress    string
-	bridgeIP      string
-	netmask       string
+	vmID     string
+	network  *vmNetwork
+	vsockCID uint32
+	console  *os.File
 }
 
 func (f *fc) runVMM(ctx context.Context,
@@ -53,20 +54,20 @@ func (f *fc) runVMM(ctx context.Context,
 	if err != nil {
 		return nil, fmt.Errorf("Failed to stat binary, %q: %v", firecrackerBinary, err)
 	}
-	socketPath := filepath.Join(vmDataPath, vmCfg.GetVmID().GetValue())
+	socketPath := f.socketPath()
 	os.Remove(socketPath)
 
-	logger.Infof("tap device name %s", f.tapDeviceName)
-	kernelArgs :=
-		fmt.Sprintf("console=ttyS0 noapic reboot=k panic=1 pci=off nomodules rw ip=%s::%s:%s::eth0:off",
-			f.ipAddress, f.bridgeIP, f.netmask)
+	logger.Infof("tap device name %s", f.network.tapDevice)
+	kernelArgs := "console=ttyS0 noapic reboot=k panic=1 pci=off nomodules rw" +
+		f.network.kernelIPArg()
 
 	cfg := firecracker.Config{
+		VMID:            f.vmID,
 		KernelArgs:      kernelArgs,
 		KernelImagePath: vmCfg.GetKernelImage(),
 		SocketPath:      socketPath,
 		Drives: []models.Drive{{
-			DriveID:      firecracker.String("1"),
+			DriveID:      firecracker.String(rootDriveID),
 			PathOnHost:   firecracker.String(vmCfg.GetRootFileSystem()),
 			IsRootDevice: firecracker.Bool(true),
 			IsReadOnly:   firecracker.Bool(false),
@@ -77,15 +78,14 @@ func (f *fc) runVMM(ctx context.Context,
 			HtEnabled:  firecracker.Bool(true),
 		},
 		NetworkInterfaces: firecracker.NetworkInterfaces{
-			firecracker.NetworkInterface{
-				StaticConfiguration: &firecracker.StaticNetworkConfiguration{
-					HostDevName: f.tapDeviceName,
-					MacAddress:  f.macAddress,
-				},
-				AllowMMDS: true,
-			},
+			f.network.networkInterface(),
 		},
 
+		VsockDevices: f.vsockDevices(),
+
+		// the node handles signals for all its VMs, see installSignalHandlers
+		ForwardSignals: []os.Signal{},
+
 		// TODO move to a constant
 		// TODO extract
 		LogLevel:    "Debug",
@@ -93,9 +93,17 @@ func (f *fc) runVMM(ctx context.Context,
 		MetricsFifo: f.getFileNameByMethod("fifo", "metrics"),
 	}
 
+	// the serial console is kept so readiness probes can look for markers
+	f.console, err = os.OpenFile(f.consolePath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create console log %s: %s", f.consolePath(), err)
+	}
+
 	cmd := firecracker.VMCommandBuilder{}.
 		WithBin(firecrackerBinary).
 		WithSocketPath(socketPath).
+		WithStdout(f.console).
+		WithStderr(f.console).
 		Build(ctx)
 
 	logger.Infof("Creating new machine definition %v", cfg)
@@ -104,9 +112,21 @@ func (f *fc) runVMM(ctx context.Context,
 		firecracker.WithProcessRunner(cmd),
 		firecracker.WithLogger(log.NewEntry(logger)))
 	if err != nil {
+		f.closeConsole()
 		return nil, fmt.Errorf("Failed creating machine: %s", err)
 	}
 
+	if b := vmCfg.GetBalloon(); b != nil {
+		if b.GetAmountMib() > vmCfg.GetMemory() {
+			f.closeConsole()
+			return nil, fmt.Errorf("Balloon size %d MiB exceeds VM memory %d MiB",
+				b.GetAmountMib(), vmCfg.GetMemory())
+		}
+		m.Handlers.FcInit = m.Handlers.FcInit.AppendAfter(
+			firecracker.CreateMachineHandlerName,
+			f.balloonHandler(b))
+	}
+
 	logger.Info("Starting machine...")
 	errChan := make(chan error, 1)
 	go func() {
@@ -117,16 +137,75 @@ func (f *fc) runVMM(ctx context.Context,
 	case err = <-errChan:
 		if err != nil {
 			log.Error("fc error", err)
+			f.closeConsole()
 			return nil, err
 		}
 	case <-time.After(3 * time.Second):
 		logger.Info("No errors after 3 seconds, assuming success")
 	}
 
-	installSignalHandlers(ctx, m)
+	f.network.updateFromCNI(m)
 	return m, err
 }
 
+// balloonHandler configures the balloon device, firecracker only allows
+// this before the instance is started
+func (f *fc) balloonHandler(b *node.BalloonConfig) firecracker.Handler {
+	return firecracker.Handler{
+		Name: "catapult.CreateBalloon",
+		Fn: func(ctx context.Context, m *firecracker.Machine) error {
+			return newFcClient(f.socketPath()).putBalloon(ctx, &balloon{
+				AmountMib:             b.GetAmountMib(),
+				DeflateOnOom:          b.GetDeflateOnOom(),
+				StatsPollingIntervalS: b.GetStatsPollingIntervalS(),
+			})
+		},
+	}
+}
+
+func (f *fc) socketPath() string {
+	return filepath.Join(vmDataPath, f.vmID)
+}
+
+func (f *fc) consolePath() string {
+	return filepath.Join(vmLogs, fmt.Sprintf("%s-console.log", f.vmID))
+}
+
+// rotateConsole keeps the console log of the previous run of the VM as
+// <path>.1, it holds the output of the crash the VM is restarted after
+func (f *fc) rotateConsole() error {
+	err := os.Rename(f.consolePath(), f.consolePath()+".1")
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (f *fc) closeConsole() {
+	if f.console != nil {
+		f.console.Close()
+		f.console = nil
+	}
+}
+
+func (f *fc) vsockPath() string {
+	return filepath.Join(vmDataPath, fmt.Sprintf("%s.vsock", f.vmID))
+}
+
+func (f *fc) vsockDevices() []firecracker.VsockDevice {
+	if f.vsockCID == 0 {
+		return nil
+	}
+
+	os.Remove(f.vsockPath())
+	return []firecracker.VsockDevice{{
+		ID:   "vsock0",
+		Path: f.vsockPath(),
+		CID:  f.vsockCID,
+	}}
+}
+
 func (f *fc) getFileNameByMethod(typ, method string) string {
 	var marker string
 	if method == "metrics" {
@@ -136,21 +215,37 @@ func (f *fc) getFileNameByMethod(typ, method string) string {
 	return filepath.Join(vmLogs, fmt.Sprintf("%s%s.%s", f.vmID, marker, typ))
 }
 
-func installSignalHandlers(ctx context.Context, m *firecracker.Machine) {
+// installSignalHandlers shuts the VMs of the node down when the node is
+// asked to terminate, it is installed once the first VM is started
+func (ns *NodeService) installSignalHandlers() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+
 	go func() {
-		// Clear some default handlers installed by the firecracker SDK:
-		signal.Reset(os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
-
-		for {
-			switch s := <-c; {
-			case s == syscall.SIGTERM || s == os.Interrupt:
-				log.Printf("Caught SIGINT, requesting clean shutdown")
-				m.Shutdown(ctx)
-			case s == syscall.SIGQUIT:
-				log.Printf("Caught SIGTERM, forcing shutdown")
-				m.StopVMM()
+		for s := range c {
+			ns.mu.RLock()
+			vms := make([]*vm, 0, len(ns.vms))
+			for _, v := range ns.vms {
+				vms = append(vms, v)
+			}
+			ns.mu.RUnlock()
+
+			for _, v := range vms {
+				v.Lock()
+				if !v.isStopped() {
+					close(v.stopped)
+				}
+				m := v.machine
+				v.Unlock()
+
+				switch s {
+				case syscall.SIGTERM, os.Interrupt:
+					log.Printf("Caught %s, requesting clean shutdown of VM %s", s, v.fc.vmID)
+					m.Shutdown(context.Background())
+				case syscall.SIGQUIT:
+					log.Printf("Caught %s, forcing shutdown of VM %s", s, v.fc.vmID)
+					m.StopVMM()
+				}
 			}
 		}
 	}()
@@ -166,27 +261,31 @@ func (f *fc) readPipe(log *log.Logger, method string) {
 		return
 	}
 
-	var output *os.File
-	if _, err := os.Stat(logPath); err != nil {
-		output, err = os.Create(logPath)
-		if err != nil {
-			log.Errorf("Failed to create log file %s, %s", logPath, err)
-		}
-	}
+	defer pipe.Close()
 
+	// the log is appended to since the VM may have been restarted
+	output, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Errorf("Failed to create log file %s, %s", logPath, err)
+		return
+	}
 	defer output.Close()
-	defer pipe.Close()
 
 	reader := bufio.NewReader(pipe)
 	writer := bufio.NewWriter(output)
 
 	for {
 		line, err := reader.ReadBytes('\n')
-		if err == nil {
-			writer.Write(line)
-			writer.Flush()
+		writer.Write(line)
+		writer.Flush()
+
+		// firecracker closed its end of the fifo, i.e. it exited
+		if err == io.EOF {
+			return
 		}
 
-		time.Sleep(1 * time.Second)
+		if err != nil {
+			time.Sleep(1 * time.Second)
+		}
 	}
 }
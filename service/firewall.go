@@ -0,0 +1,250 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// firewall programs the nftables rules of the tap devices of VMs. Every VM
+// gets tables of its own named after its tap device, a netdev one pinning
+// the tap to the VM's MAC and IP address and one filtering the traffic from
+// and to the VM. Traffic between VMs on a bridge is never routed, so the
+// filter table of bridged VMs is in the bridge family, where connection
+// tracking needs the nf_conntrack_bridge module of Linux 5.3 or later
+type firewall struct {
+	log          *log.Logger
+	antiSpoofing bool
+}
+
+func newFirewall(logger *log.Logger, cfg NetworkConfig) *firewall {
+	return &firewall{
+		log:          logger,
+		antiSpoofing: !cfg.DisableAntiSpoofing,
+	}
+}
+
+// apply replaces the rules of the VM's tap device, it is unrestricted if fw
+// is nil
+func (f *firewall) apply(n *vmNetwork, fw *node.Firewall) error {
+	if n.cni != nil {
+		if fw != nil {
+			return fmt.Errorf("Firewall rules are not supported with the %s network driver", networkCNI)
+		}
+
+		return nil
+	}
+
+	if !f.antiSpoofing && fw == nil {
+		return nil
+	}
+
+	script, err := firewallRules(n, fw, f.antiSpoofing)
+	if err != nil {
+		return err
+	}
+
+	f.log.Infof("Applying firewall of tap device %s", n.tapDevice)
+	err = runNft(script)
+	if err != nil && fw != nil && filterFamily(n) == "bridge" {
+		return fmt.Errorf("Failed to load the firewall of tap device %s, firewalls of bridged VMs "+
+			"need the nf_conntrack_bridge module of Linux 5.3 or later: %s", n.tapDevice, err)
+	}
+
+	return err
+}
+
+// remove deletes the rules of the VM's tap device, rules already gone are
+// not an error
+func (f *firewall) remove(n *vmNetwork) error {
+	if n.cni != nil || n.tapDevice == "" {
+		return nil
+	}
+
+	if !f.antiSpoofing {
+		// the VM may have firewall rules still
+		if _, err := util.ExecuteCommand("nft", "list", "table", filterFamily(n), firewallTable(n)); err != nil {
+			return nil
+		}
+	}
+
+	var script strings.Builder
+	deleteTable(&script, "netdev", firewallTable(n))
+	deleteTable(&script, filterFamily(n), firewallTable(n))
+	return runNft(script.String())
+}
+
+// firewallTable returns the name of the tables of a VM's tap device
+func firewallTable(n *vmNetwork) string {
+	return "catapult_" + strings.Replace(n.tapDevice, "-", "_", -1)
+}
+
+func filterFamily(n *vmNetwork) string {
+	if n.driver == networkRouted {
+		return "inet"
+	}
+
+	return "bridge"
+}
+
+// deleteTable deletes a table, declaring it first so deleting a table that
+// doesn't exist is not an error
+func deleteTable(script *strings.Builder, family, table string) {
+	fmt.Fprintf(script, "table %s %s\n", family, table)
+	fmt.Fprintf(script, "delete table %s %s\n", family, table)
+}
+
+// firewallRules returns the nft script replacing the tables of the VM's tap
+// device, it is applied atomically
+func firewallRules(n *vmNetwork, fw *node.Firewall, antiSpoofing bool) (string, error) {
+	var script strings.Builder
+	table := firewallTable(n)
+	family := filterFamily(n)
+
+	deleteTable(&script, "netdev", table)
+	deleteTable(&script, family, table)
+
+	if antiSpoofing {
+		fmt.Fprintf(&script, "table netdev %s {\n", table)
+		fmt.Fprintf(&script, "\tchain antispoof {\n")
+		fmt.Fprintf(&script, "\t\ttype filter hook ingress device %q priority 0; policy drop;\n", n.tapDevice)
+		fmt.Fprintf(&script, "\t\tether saddr != %s drop\n", n.macAddress)
+		fmt.Fprintf(&script, "\t\tether type arp arp saddr ether %s arp saddr ip %s accept\n", n.macAddress, n.ip)
+		fmt.Fprintf(&script, "\t\tether type ip ip saddr %s accept\n", n.ip)
+		fmt.Fprintf(&script, "\t}\n}\n")
+	}
+
+	if fw == nil {
+		return script.String(), nil
+	}
+
+	ingress, err := firewallChain(family, "ip saddr", fw.GetIngress())
+	if err != nil {
+		return "", err
+	}
+
+	egress, err := firewallChain(family, "ip daddr", fw.GetEgress())
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&script, "table %s %s {\n", family, table)
+	fmt.Fprintf(&script, "\tchain forward {\n")
+	fmt.Fprintf(&script, "\t\ttype filter hook forward priority 0; policy accept;\n")
+	fmt.Fprintf(&script, "\t\tiifname %q jump egress\n", n.tapDevice)
+	fmt.Fprintf(&script, "\t\toifname %q jump ingress\n", n.tapDevice)
+	fmt.Fprintf(&script, "\t}\n")
+	// traffic between the VM and the host itself
+	fmt.Fprintf(&script, "\tchain input {\n")
+	fmt.Fprintf(&script, "\t\ttype filter hook input priority 0; policy accept;\n")
+	fmt.Fprintf(&script, "\t\tiifname %q jump egress\n", n.tapDevice)
+	fmt.Fprintf(&script, "\t}\n")
+	fmt.Fprintf(&script, "\tchain output {\n")
+	fmt.Fprintf(&script, "\t\ttype filter hook output priority 0; policy accept;\n")
+	fmt.Fprintf(&script, "\t\toifname %q jump ingress\n", n.tapDevice)
+	fmt.Fprintf(&script, "\t}\n")
+	fmt.Fprintf(&script, "\tchain ingress {\n%s\t}\n", ingress)
+	fmt.Fprintf(&script, "\tchain egress {\n%s\t}\n", egress)
+	fmt.Fprintf(&script, "}\n")
+
+	return script.String(), nil
+}
+
+// firewallChain returns the rules of a chain accepting the traffic the
+// rules allow and replies to it, addr matches the remote address. Bridge
+// chains see ARP too, which has to pass for the VM to reach anything
+func firewallChain(family, addr string, rules []*node.FirewallRule) (string, error) {
+	var chain strings.Builder
+	fmt.Fprintf(&chain, "\t\tct state established,related accept\n")
+	for _, rule := range rules {
+		match, err := firewallMatch(addr, rule)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&chain, "\t\t%saccept\n", match)
+	}
+	if family == "bridge" {
+		fmt.Fprintf(&chain, "\t\tether type arp accept\n")
+	}
+	fmt.Fprintf(&chain, "\t\tdrop\n")
+
+	return chain.String(), nil
+}
+
+// firewallMatch returns the nft expressions matching a rule
+func firewallMatch(addr string, rule *node.FirewallRule) (string, error) {
+	var match strings.Builder
+	if rule.GetCidr() != "" {
+		cidr := rule.GetCidr()
+		if !strings.Contains(cidr, "/") {
+			cidr += "/32"
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil || ipNet.IP.To4() == nil {
+			return "", fmt.Errorf("Invalid firewall rule CIDR %q", rule.GetCidr())
+		}
+
+		fmt.Fprintf(&match, "%s %s ", addr, ipNet)
+	}
+
+	protocol := strings.ToLower(rule.GetProtocol())
+	from, to := rule.GetFromPort(), rule.GetToPort()
+	if to == 0 {
+		to = from
+	}
+
+	switch protocol {
+	case "tcp", "udp":
+		if from > 65535 || to > 65535 || to < from || (from == 0 && to != 0) {
+			return "", fmt.Errorf("Invalid firewall rule port range %d-%d", rule.GetFromPort(), rule.GetToPort())
+		}
+
+		if from == 0 {
+			fmt.Fprintf(&match, "meta l4proto %s ", protocol)
+		} else if from == to {
+			fmt.Fprintf(&match, "%s dport %d ", protocol, from)
+		} else {
+			fmt.Fprintf(&match, "%s dport %d-%d ", protocol, from, to)
+		}
+	case "icmp", "":
+		if from != 0 || to != 0 {
+			return "", fmt.Errorf("Firewall rule ports need the tcp or udp protocol")
+		}
+
+		if protocol != "" {
+			fmt.Fprintf(&match, "meta l4proto %s ", protocol)
+		}
+	default:
+		return "", fmt.Errorf("Unsupported firewall rule protocol %q", rule.GetProtocol())
+	}
+
+	return match.String(), nil
+}
+
+func runNft(script string) error {
+	f, err := ioutil.TempFile("", "catapult-nft")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(script)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = util.ExecuteCommand("nft", "-f", f.Name())
+	return err
+}
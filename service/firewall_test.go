@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+func TestFirewallRules(t *testing.T) {
+	n := &vmNetwork{
+		driver:     networkBridge,
+		tapDevice:  "fc-abcdef",
+		macAddress: "02:00:00:00:00:01",
+		ip:         "172.17.0.2",
+	}
+
+	fw := &node.Firewall{
+		Ingress: []*node.FirewallRule{
+			{Cidr: "10.0.0.0/8", Protocol: "tcp", FromPort: 22},
+			{Protocol: "TCP", FromPort: 8000, ToPort: 8080},
+			{Cidr: "192.168.1.1", Protocol: "icmp"},
+		},
+		Egress: []*node.FirewallRule{{}},
+	}
+
+	script, err := firewallRules(n, fw, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"delete table netdev catapult_fc_abcdef\n",
+		"table bridge catapult_fc_abcdef {\n",
+		"type filter hook ingress device \"fc-abcdef\" priority 0; policy drop;\n",
+		"ether saddr != 02:00:00:00:00:01 drop\n",
+		"ether type ip ip saddr 172.17.0.2 accept\n",
+		"ip saddr 10.0.0.0/8 tcp dport 22 accept\n",
+		"\t\ttcp dport 8000-8080 accept\n",
+		"ip saddr 192.168.1.1/32 meta l4proto icmp accept\n",
+		"\t\tether type arp accept\n\t\tdrop\n\t}\n\tchain egress {\n",
+		"\tchain egress {\n\t\tct state established,related accept\n\t\taccept\n\t\tether type arp accept\n\t\tdrop\n",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("%q missing from\n%s", expected, script)
+		}
+	}
+
+	n.driver = networkRouted
+	script, err = firewallRules(n, fw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "arp") || !strings.Contains(script, "table inet catapult_fc_abcdef {\n") {
+		t.Errorf("unexpected routed script\n%s", script)
+	}
+
+	script, err = firewallRules(n, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "{") || !strings.Contains(script, "delete table inet catapult_fc_abcdef\n") {
+		t.Errorf("unexpected script without rules\n%s", script)
+	}
+
+	for _, rule := range []*node.FirewallRule{
+		{Cidr: "10.0.0.0/33"},
+		{Cidr: "fd00::/64"},
+		{Protocol: "sctp"},
+		{Protocol: "icmp", FromPort: 1},
+		{Protocol: "udp", FromPort: 80, ToPort: 53},
+		{Protocol: "udp", ToPort: 53},
+		{Protocol: "udp", FromPort: 70000},
+	} {
+		fw = &node.Firewall{Egress: []*node.FirewallRule{rule}}
+		if _, err = firewallRules(n, fw, true); err == nil {
+			t.Errorf("invalid rule %v was accepted", rule)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"os"
+
+	"github.com/containers/image/signature"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultPolicyPath is where containers/image tools keep the system policy
+const defaultPolicyPath = "/etc/containers/policy.json"
+
+// policyContext loads the signature policy pulled images are checked
+// against. It is read on every pull so policy changes apply right away
+func (is *imageStore) policyContext() (*signature.PolicyContext, error) {
+	path := is.policyPath
+	if path == "" {
+		if _, err := os.Stat(defaultPolicyPath); err == nil {
+			path = defaultPolicyPath
+		}
+	}
+
+	var policy *signature.Policy
+	if path == "" {
+		is.log.Warn("No signature policy configured, accepting any image")
+		policy = &signature.Policy{Default: []signature.PolicyRequirement{
+			signature.NewPRInsecureAcceptAnything(),
+		}}
+	} else {
+		var err error
+		policy, err = signature.NewPolicyFromFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to load signature policy %s", path)
+		}
+	}
+
+	return signature.NewPolicyContext(policy)
+}
+
+// pullError turns images rejected by the signature policy into
+// PermissionDenied errors
+func pullError(err error) error {
+	switch errors.Cause(err).(type) {
+	case signature.PolicyRequirementError, signature.InvalidSignatureError:
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+
+	return err
+}
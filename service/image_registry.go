@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containers/image/pkg/sysregistriesv2"
+	"github.com/containers/image/types"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+// registriesConfPath is where the registries configured for the node are
+// written in the format containers/image reads mirrors from
+const registriesConfPath = "/var/lib/catapult-node/registries.conf"
+
+// systemContext returns the settings used when talking to registries, auth
+// overrides the credentials found in the auth file
+func (is *imageStore) systemContext(auth *types.DockerAuthConfig) *types.SystemContext {
+	return &types.SystemContext{
+		RegistriesDirPath:        is.registriesDir,
+		SystemRegistriesConfPath: is.registriesConf,
+		AuthFilePath:             is.authFile,
+		DockerPerHostCertDirPath: is.certDir,
+		DockerAuthConfig:         auth,
+	}
+}
+
+// writeRegistriesConf writes the registries from the node config as a
+// registries.conf and returns its path, an empty path means the system
+// configuration is used
+func writeRegistriesConf(registries []RegistryConfig) (string, error) {
+	if len(registries) == 0 {
+		return "", nil
+	}
+
+	conf := sysregistriesv2.V2RegistriesConf{}
+	for _, r := range registries {
+		registry := sysregistriesv2.Registry{
+			Prefix: r.Prefix,
+			Endpoint: sysregistriesv2.Endpoint{
+				Location: r.Location,
+				Insecure: r.Insecure,
+			},
+		}
+		for _, m := range r.Mirrors {
+			registry.Mirrors = append(registry.Mirrors, sysregistriesv2.Endpoint{
+				Location: m.Location,
+				Insecure: m.Insecure,
+			})
+		}
+		conf.Registries = append(conf.Registries, registry)
+	}
+
+	err := os.MkdirAll(filepath.Dir(registriesConfPath), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(registriesConfPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return registriesConfPath, toml.NewEncoder(f).Encode(conf)
+}
+
+// credentials returns the registry credentials to pull with, either given
+// inline or referring to credentials in the node config. nil means the
+// auth file is used
+func (is *imageStore) credentials(creds *node.RegistryCredentials) (*types.DockerAuthConfig, error) {
+	if creds.GetRef() != "" {
+		c, ok := is.namedCredentials[creds.GetRef()]
+		if !ok {
+			return nil, fmt.Errorf("Unknown registry credentials %q", creds.GetRef())
+		}
+
+		return &types.DockerAuthConfig{
+			Username: c.Username,
+			Password: c.Password,
+		}, nil
+	}
+
+	if creds.GetUsername() == "" {
+		return nil, nil
+	}
+
+	return &types.DockerAuthConfig{
+		Username: creds.GetUsername(),
+		Password: creds.GetPassword(),
+	}, nil
+}
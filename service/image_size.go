@@ -0,0 +1,160 @@
+package service
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containers/image/pkg/compression"
+	digest "github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// fsBlockSize is what file sizes are rounded up to, directories and
+	// other entries are assumed to take a block each
+	fsBlockSize = 4096
+	// fsOverheadMib covers the journal and metadata of a new filesystem
+	fsOverheadMib = 64
+
+	defaultSizeHeadroomPercent = 20
+)
+
+// imageSizes describes how much space an image needs
+type imageSizes struct {
+	// compressed is the size of the blobs in the store
+	compressed int64
+	// unpacked is the size of the files of all layers once unpacked
+	unpacked int64
+	// requiredMib is the size of a volume the image fits on
+	requiredMib int64
+}
+
+// imageSizes returns the sizes of the named image, headroom is the percentage
+// of the unpacked size added to leave room for the guest's writes
+func (is *imageStore) imageSizes(name string, headroom int64) (*imageSizes, error) {
+	// the image is pinned rather than locked, reading through its layers
+	// takes a while
+	p, err := is.pin(name)
+	if err != nil {
+		return nil, err
+	}
+	defer is.unpin(p)
+
+	compressed := p.manifest.Config.Size
+	for _, layer := range p.manifest.Layers {
+		compressed += layer.Size
+	}
+
+	// files removed by later layers are counted anyway, the estimate errs
+	// on the large side
+	var unpacked int64
+	for _, layer := range p.manifest.Layers {
+		size, err := is.layerSize(layer.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read layer %s: %s", layer.Digest, err)
+		}
+		unpacked += size
+	}
+
+	return &imageSizes{
+		compressed:  compressed,
+		unpacked:    unpacked,
+		requiredMib: requiredSizeMib(unpacked, headroom),
+	}, nil
+}
+
+// find returns the descriptor of the named image, callers must hold the
+// lock
+func (is *imageStore) find(name string) (*imgspecv1.Descriptor, error) {
+	index, err := is.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range index.Manifests {
+		if index.Manifests[i].Annotations[imgspecv1.AnnotationRefName] == name {
+			return &index.Manifests[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Image %s not found", name)
+}
+
+// layerSize returns the space the entries of a layer take, layers are
+// immutable so the result is cached by digest. The layer must be pinned
+func (is *imageStore) layerSize(d digest.Digest) (int64, error) {
+	is.Lock()
+	size, ok := is.layerSizes[d]
+	is.Unlock()
+	if ok {
+		return size, nil
+	}
+
+	size, err := is.readLayerSize(d)
+	if err != nil {
+		return 0, err
+	}
+
+	is.Lock()
+	defer is.Unlock()
+	if is.layerSizes == nil {
+		is.layerSizes = make(map[digest.Digest]int64)
+	}
+	is.layerSizes[d] = size
+
+	return size, nil
+}
+
+// readLayerSize reads through a layer adding up the space its entries take
+func (is *imageStore) readLayerSize(d digest.Digest) (int64, error) {
+	f, err := os.Open(is.blobPath(d))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r, _, err := compression.AutoDecompress(f)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var size int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+			size += roundUp(hdr.Size, fsBlockSize)
+		case tar.TypeLink:
+		default:
+			size += fsBlockSize
+		}
+	}
+
+	return size, nil
+}
+
+// requiredSizeMib adds the headroom and the filesystem's own overhead to the
+// unpacked size of an image
+func requiredSizeMib(unpacked, headroom int64) int64 {
+	if headroom <= 0 {
+		headroom = defaultSizeHeadroomPercent
+	}
+
+	size := unpacked + unpacked*headroom/100 + fsOverheadMib*mib
+	return roundUp(size, mib) / mib
+}
+
+func roundUp(n, to int64) int64 {
+	return (n + to - 1) / to * to
+}
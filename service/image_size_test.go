@@ -0,0 +1,61 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func tarGz(t *testing.T, files map[string]int) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0755})
+	for name, size := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(size)})
+		tw.Write(make([]byte, size))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+func TestImageSizes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	is := newImageStore(logrus.New(), ImagesConfig{Dir: dir})
+	base := putBlob(t, is, tarGz(t, map[string]int{"etc/hosts": 100, "etc/big": 10000}))
+	app := putBlob(t, is, tarGz(t, map[string]int{"etc/app": 5000}))
+	index, _ := is.readIndex()
+	index.Manifests = append(index.Manifests, putImage(t, is, "app", base, app))
+	if err = is.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes, err := is.imageSizes("app", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a block per directory, files rounded up to blocks
+	expected := int64((1+1+3)+(1+2)) * fsBlockSize
+	if sizes.unpacked != expected {
+		t.Errorf("\n\tGOT: %d \n\tEXPECTED: %d", sizes.unpacked, expected)
+	}
+
+	if sizes.requiredMib != fsOverheadMib+1 {
+		t.Errorf("required %d MiB, expected %d", sizes.requiredMib, fsOverheadMib+1)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/image/docker"
+	"github.com/containers/image/transports"
+	"github.com/containers/image/transports/alltransports"
+	"github.com/containers/image/types"
+)
+
+// imageSource parses the image name given to CreateDrive. Names may carry
+// any transport alltransports knows, e.g. oci-archive:/srv/alpine.tar, and
+// plain names are pulled from a registry. It also returns the name the
+// image is stored under
+func imageSource(imageName string) (types.ImageReference, string, error) {
+	parts := strings.SplitN(imageName, ":", 2)
+	if len(parts) < 2 || transports.Get(parts[0]) == nil {
+		// registry references like localhost:5000/alpine contain colons
+		// too, but never a known transport before the first one
+		imageName = fmt.Sprintf("%s://%s", docker.Transport.Name(), imageName)
+		parts = strings.SplitN(imageName, ":", 2)
+	}
+
+	ref, err := alltransports.ParseImageName(imageName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if ref.Transport().Name() == docker.Transport.Name() {
+		return ref, strings.TrimPrefix(parts[1], "//"), nil
+	}
+
+	return ref, storedImageName(ref.Transport().Name(), parts[1]), nil
+}
+
+// storedImageName turns a transport specific reference, usually a path, into
+// a valid OCI reference name
+func storedImageName(transport, reference string) string {
+	components := []string{transport}
+	for _, part := range strings.Split(reference, "/") {
+		part = strings.Trim(strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '-'
+		}, part), "-")
+
+		for strings.Contains(part, "--") {
+			part = strings.Replace(part, "--", "-", -1)
+		}
+
+		if part != "" {
+			components = append(components, part)
+		}
+	}
+
+	return strings.Join(components, "/")
+}
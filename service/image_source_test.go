@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestImageSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		stored    string
+	}{
+		{"alpine:3.10", "docker", "alpine:3.10"},
+		{"localhost:5000/alpine", "docker", "localhost:5000/alpine"},
+		{"docker://alpine:3.10", "docker", "alpine:3.10"},
+		{"dir:/srv/alpine", "dir", "dir/srv/alpine"},
+		{"oci:/srv/layout:alpine", "oci", "oci/srv/layout-alpine"},
+	}
+
+	for _, test := range tests {
+		ref, stored, err := imageSource(test.name)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if ref.Transport().Name() != test.transport || stored != test.stored {
+			t.Errorf("%s\n\tGOT: %s %s \n\tEXPECTED: %s %s", test.name,
+				ref.Transport().Name(), stored, test.transport, test.stored)
+		}
+	}
+}
+
+func TestStoredImageName(t *testing.T) {
+	expected := "oci-archive/srv/images/alpine-3-10-tar"
+	if got := storedImageName("oci-archive", "/srv/images//alpine_3.10.tar"); got != expected {
+		t.Errorf("\n\tGOT: %s \n\tEXPECTED: %s", got, expected)
+	}
+}
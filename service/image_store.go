@@ -0,0 +1,559 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/containers/image/types"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultImageDir = "/var/lib/catapult-node/images"
+
+	// stagingDir holds the layouts pulls copy images to and the ones images
+	// are unpacked from
+	stagingDir = "staging"
+)
+
+// imageStore is a single OCI layout shared by all pulled images. Blobs are
+// stored by digest so layers already present are not pulled again, and
+// images are referenced by name in the layout's index
+type imageStore struct {
+	sync.Mutex
+	log   *log.Logger
+	dir   string
+	quota int64
+
+	policyPath       string
+	registriesDir    string
+	registriesConf   string
+	authFile         string
+	certDir          string
+	namedCredentials map[string]CredentialConfig
+
+	// layerSizes caches the unpacked size of layers
+	layerSizes map[digest.Digest]int64
+	// pinned counts the users of blobs gc must keep even if no image
+	// references them anymore
+	pinned map[digest.Digest]int
+}
+
+// pinnedImage is an image whose blobs are kept until it is unpinned
+type pinnedImage struct {
+	desc     imgspecv1.Descriptor
+	manifest *imgspecv1.Manifest
+	blobs    []digest.Digest
+}
+
+type storedImage struct {
+	name   string
+	digest string
+	size   int64
+}
+
+func newImageStore(logger *log.Logger, cfg ImagesConfig) *imageStore {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultImageDir
+	}
+
+	// pulls interrupted by a restart of the node leave their staging
+	// layouts behind
+	os.RemoveAll(filepath.Join(dir, stagingDir))
+
+	registriesConf, err := writeRegistriesConf(cfg.Registries)
+	if err != nil {
+		logger.Errorf("Failed to write registries configuration, using the system one: %s", err)
+		registriesConf = ""
+	}
+
+	return &imageStore{
+		log:              logger,
+		dir:              dir,
+		quota:            cfg.QuotaMib * mib,
+		policyPath:       cfg.Policy,
+		registriesDir:    cfg.RegistriesDir,
+		registriesConf:   registriesConf,
+		authFile:         cfg.AuthFile,
+		certDir:          cfg.CertDir,
+		namedCredentials: cfg.Credentials,
+	}
+}
+
+// imagePath returns the path of an image as understood by unpackImage
+func (is *imageStore) imagePath(name string) string {
+	return fmt.Sprintf("%s:%s", is.dir, name)
+}
+
+// splitImagePath splits an image path into the OCI layout directory and the
+// image reference in it, paths without a reference come from the time every
+// image had its own layout
+func splitImagePath(imagePath string) (string, string) {
+	i := strings.Index(imagePath, ":")
+	if i < 0 {
+		return imagePath, tag
+	}
+
+	return imagePath[:i], imagePath[i+1:]
+}
+
+func (is *imageStore) blobPath(d digest.Digest) string {
+	return filepath.Join(is.dir, "blobs", d.Algorithm().String(), d.Hex())
+}
+
+func (is *imageStore) readIndex() (*imgspecv1.Index, error) {
+	index := new(imgspecv1.Index)
+	b, err := ioutil.ReadFile(filepath.Join(is.dir, "index.json"))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return index, json.Unmarshal(b, index)
+}
+
+func (is *imageStore) writeIndex(index *imgspecv1.Index) error {
+	b, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(is.dir, "index.json")
+	err = ioutil.WriteFile(path+".tmp", b, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func (is *imageStore) readManifest(d digest.Digest) (*imgspecv1.Manifest, error) {
+	b, err := ioutil.ReadFile(is.blobPath(d))
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(imgspecv1.Manifest)
+	return m, json.Unmarshal(b, m)
+}
+
+// newStaging creates the layout a pull copies an image to, so the lock
+// isn't held while copying. It starts empty, stagingReference links the
+// blobs the pull needs from the store
+func (is *imageStore) newStaging() (string, error) {
+	err := os.MkdirAll(filepath.Join(is.dir, stagingDir), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return ioutil.TempDir(filepath.Join(is.dir, stagingDir), "pull")
+}
+
+// linkBlob links a blob of the store into a staging layout, blobs the store
+// doesn't have are not an error
+func (is *imageStore) linkBlob(staging string, d digest.Digest) error {
+	err := d.Validate()
+	if err != nil {
+		return err
+	}
+
+	is.Lock()
+	defer is.Unlock()
+
+	target := filepath.Join(staging, "blobs", d.Algorithm().String(), d.Hex())
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.Link(is.blobPath(d), target)
+	if os.IsNotExist(err) || os.IsExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// stagingReference is the destination of a pull to a staging layout, the
+// blobs the pull would fetch are linked from the store if it has them
+type stagingReference struct {
+	types.ImageReference
+	store   *imageStore
+	staging string
+}
+
+func (r *stagingReference) NewImageDestination(ctx context.Context,
+	sys *types.SystemContext) (types.ImageDestination, error) {
+	dest, err := r.ImageReference.NewImageDestination(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stagingDestination{ImageDestination: dest, store: r.store, staging: r.staging}, nil
+}
+
+type stagingDestination struct {
+	types.ImageDestination
+	store   *imageStore
+	staging string
+}
+
+func (d *stagingDestination) TryReusingBlob(ctx context.Context, info types.BlobInfo,
+	cache types.BlobInfoCache, canSubstitute bool) (bool, types.BlobInfo, error) {
+	if info.Digest != "" {
+		err := d.store.linkBlob(d.staging, info.Digest)
+		if err != nil {
+			return false, types.BlobInfo{}, err
+		}
+	}
+
+	return d.ImageDestination.TryReusingBlob(ctx, info, cache, canSubstitute)
+}
+
+// commitStaging moves the named image pulled to a staging layout into the
+// store, replacing an image of the same name. Callers must hold the lock
+func (is *imageStore) commitStaging(staging, name string) error {
+	stagingIndex, err := (&imageStore{dir: staging}).readIndex()
+	if err != nil {
+		return err
+	}
+
+	var desc *imgspecv1.Descriptor
+	for i := range stagingIndex.Manifests {
+		if stagingIndex.Manifests[i].Annotations[imgspecv1.AnnotationRefName] == name {
+			desc = &stagingIndex.Manifests[i]
+		}
+	}
+
+	if desc == nil {
+		return fmt.Errorf("Pulled image %s not found in %s", name, staging)
+	}
+
+	// blobs collected while the image was pulled are moved back too
+	err = filepath.Walk(filepath.Join(staging, "blobs"), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(is.dir, rel)
+		if _, err = os.Stat(target); err == nil {
+			return nil
+		}
+
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+
+		return os.Rename(path, target)
+	})
+	if err != nil {
+		return err
+	}
+
+	index, err := is.readIndex()
+	if err != nil {
+		return err
+	}
+
+	manifests := index.Manifests[:0]
+	for _, d := range index.Manifests {
+		if d.Annotations[imgspecv1.AnnotationRefName] != name {
+			manifests = append(manifests, d)
+		}
+	}
+	index.Versioned = stagingIndex.Versioned
+	index.Manifests = append(manifests, *desc)
+
+	err = writeLayoutFile(is.dir)
+	if err != nil {
+		return err
+	}
+
+	return is.writeIndex(index)
+}
+
+func writeLayoutFile(dir string) error {
+	return ioutil.WriteFile(filepath.Join(dir, imgspecv1.ImageLayoutFile),
+		[]byte(fmt.Sprintf(`{"imageLayoutVersion": "%s"}`, imgspecv1.ImageLayoutVersion)), 0644)
+}
+
+// pin resolves the named image and keeps its blobs from being collected
+// until unpin is called, even if the image is removed or replaced meanwhile
+func (is *imageStore) pin(name string) (*pinnedImage, error) {
+	is.Lock()
+	defer is.Unlock()
+
+	desc, err := is.find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := is.readManifest(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := []digest.Digest{desc.Digest, m.Config.Digest}
+	for _, layer := range m.Layers {
+		blobs = append(blobs, layer.Digest)
+	}
+
+	if is.pinned == nil {
+		is.pinned = make(map[digest.Digest]int)
+	}
+	for _, d := range blobs {
+		is.pinned[d]++
+	}
+
+	return &pinnedImage{desc: *desc, manifest: m, blobs: blobs}, nil
+}
+
+func (is *imageStore) unpin(p *pinnedImage) {
+	is.Lock()
+	defer is.Unlock()
+
+	for _, d := range p.blobs {
+		is.pinned[d]--
+		if is.pinned[d] <= 0 {
+			delete(is.pinned, d)
+		}
+	}
+}
+
+// newView creates a layout holding only a pinned image, so it can be
+// unpacked without the lock while other images are pulled or removed
+func (is *imageStore) newView(p *pinnedImage) (string, error) {
+	err := os.MkdirAll(filepath.Join(is.dir, stagingDir), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := ioutil.TempDir(filepath.Join(is.dir, stagingDir), "unpack")
+	if err != nil {
+		return "", err
+	}
+
+	err = is.fillView(view, p)
+	if err != nil {
+		os.RemoveAll(view)
+		return "", err
+	}
+
+	return view, nil
+}
+
+func (is *imageStore) fillView(view string, p *pinnedImage) error {
+	for _, d := range p.blobs {
+		target := filepath.Join(view, "blobs", d.Algorithm().String(), d.Hex())
+		err := os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+
+		err = os.Link(is.blobPath(d), target)
+		if err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+
+	err := writeLayoutFile(view)
+	if err != nil {
+		return err
+	}
+
+	return (&imageStore{dir: view}).writeIndex(&imgspecv1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []imgspecv1.Descriptor{p.desc},
+	})
+}
+
+// list returns the images referenced in the store, callers must hold the
+// lock
+func (is *imageStore) list() ([]*storedImage, error) {
+	index, err := is.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]*storedImage, 0, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		size, err := is.imageSize(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, &storedImage{
+			name:   desc.Annotations[imgspecv1.AnnotationRefName],
+			digest: desc.Digest.String(),
+			size:   size,
+		})
+	}
+
+	return images, nil
+}
+
+// imageSize sums the blobs making up the image with the given manifest
+func (is *imageStore) imageSize(manifestDigest digest.Digest) (int64, error) {
+	m, err := is.readManifest(manifestDigest)
+	if err != nil {
+		return 0, err
+	}
+
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+
+	return size, nil
+}
+
+// remove drops the reference to the named image and collects the blobs
+// no other image uses
+func (is *imageStore) remove(name string) error {
+	is.Lock()
+	defer is.Unlock()
+
+	index, err := is.readIndex()
+	if err != nil {
+		return err
+	}
+
+	manifests := index.Manifests[:0]
+	for _, desc := range index.Manifests {
+		if desc.Annotations[imgspecv1.AnnotationRefName] != name {
+			manifests = append(manifests, desc)
+		}
+	}
+
+	if len(manifests) == len(index.Manifests) {
+		return fmt.Errorf("Image %s not found", name)
+	}
+
+	index.Manifests = manifests
+	err = is.writeIndex(index)
+	if err != nil {
+		return err
+	}
+
+	_, err = is.gc()
+	return err
+}
+
+// gc removes the blobs neither referenced by any image nor pinned and
+// returns the number of bytes freed, callers must hold the lock
+func (is *imageStore) gc() (int64, error) {
+	index, err := is.readIndex()
+	if err != nil {
+		return 0, err
+	}
+
+	used := make(map[digest.Digest]bool)
+	for _, desc := range index.Manifests {
+		used[desc.Digest] = true
+		m, err := is.readManifest(desc.Digest)
+		if err != nil {
+			return 0, err
+		}
+
+		used[m.Config.Digest] = true
+		for _, layer := range m.Layers {
+			used[layer.Digest] = true
+		}
+	}
+
+	var freed int64
+	blobsDir := filepath.Join(is.dir, "blobs")
+	err = filepath.Walk(blobsDir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		d := digest.NewDigestFromHex(filepath.Base(filepath.Dir(path)), info.Name())
+		if used[d] || is.pinned[d] > 0 {
+			return nil
+		}
+
+		is.log.Debugf("Removing unreferenced blob %s", d)
+		err = os.Remove(path)
+		if err == nil {
+			freed += info.Size()
+		}
+		return err
+	})
+
+	if freed > 0 {
+		is.log.Infof("Image store garbage collection freed %d bytes", freed)
+	}
+
+	return freed, err
+}
+
+// usage returns the bytes used by the blobs in the store
+func (is *imageStore) usage() (int64, error) {
+	var usage int64
+	err := filepath.Walk(filepath.Join(is.dir, "blobs"), func(_ string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			usage += info.Size()
+		}
+		return nil
+	})
+
+	return usage, err
+}
+
+// ensureQuota makes room for new pulls by collecting unreferenced blobs,
+// failing if the store is still over its quota. Callers must hold the lock
+func (is *imageStore) ensureQuota() error {
+	if is.quota <= 0 {
+		return nil
+	}
+
+	usage, err := is.usage()
+	if err != nil || usage < is.quota {
+		return err
+	}
+
+	_, err = is.gc()
+	if err != nil {
+		return err
+	}
+
+	usage, err = is.usage()
+	if err != nil {
+		return err
+	}
+
+	if usage >= is.quota {
+		return fmt.Errorf("Image store uses %d of its %d bytes quota, remove images first",
+			usage, is.quota)
+	}
+
+	return nil
+}
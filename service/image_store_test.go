@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+func putBlob(t *testing.T, is *imageStore, content []byte) imgspecv1.Descriptor {
+	d := digest.FromBytes(content)
+	os.MkdirAll(filepath.Dir(is.blobPath(d)), 0755)
+	if err := ioutil.WriteFile(is.blobPath(d), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return imgspecv1.Descriptor{Digest: d, Size: int64(len(content))}
+}
+
+func putImage(t *testing.T, is *imageStore, name string, layers ...imgspecv1.Descriptor) imgspecv1.Descriptor {
+	config := putBlob(t, is, []byte(fmt.Sprintf(
+		`{"architecture": "amd64", "os": "linux", "author": %q, "rootfs": {"type": "layers"}}`, name)))
+	config.MediaType = imgspecv1.MediaTypeImageConfig
+	for i := range layers {
+		layers[i].MediaType = imgspecv1.MediaTypeImageLayerGzip
+	}
+
+	m, _ := json.Marshal(&imgspecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    config,
+		Layers:    layers,
+	})
+	desc := putBlob(t, is, m)
+	desc.MediaType = imgspecv1.MediaTypeImageManifest
+	desc.Annotations = map[string]string{imgspecv1.AnnotationRefName: name}
+
+	return desc
+}
+
+func TestImageStoreRemoveKeepsSharedLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	is := newImageStore(logrus.New(), ImagesConfig{Dir: dir})
+	shared := putBlob(t, is, []byte("shared layer"))
+	own := putBlob(t, is, []byte("own layer"))
+	index := &imgspecv1.Index{Manifests: []imgspecv1.Descriptor{
+		putImage(t, is, "a", shared, own),
+		putImage(t, is, "b", shared),
+	}}
+	if err = is.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = is.remove("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(is.blobPath(own.Digest)); !os.IsNotExist(err) {
+		t.Error("layer only used by the removed image was kept")
+	}
+
+	if _, err = os.Stat(is.blobPath(shared.Digest)); err != nil {
+		t.Errorf("shared layer was removed: %s", err)
+	}
+
+	images, err := is.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(images) != 1 || images[0].name != "b" {
+		t.Errorf("unexpected images left %v", images)
+	}
+}
+
+func TestPinnedImageOutlivesRemoval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	is := newImageStore(logrus.New(), ImagesConfig{Dir: dir})
+	layer := putBlob(t, is, []byte("layer"))
+	desc := putImage(t, is, "a", layer)
+	if err = is.writeIndex(&imgspecv1.Index{Manifests: []imgspecv1.Descriptor{desc}}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := is.pin("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = is.remove("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := is.newView(p)
+	if err != nil {
+		t.Fatalf("pinned image lost its blobs: %s", err)
+	}
+	defer os.RemoveAll(view)
+
+	index, err := (&imageStore{dir: view}).readIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Digest != desc.Digest {
+		t.Errorf("unexpected view index %v", index.Manifests)
+	}
+
+	is.unpin(p)
+	is.Lock()
+	_, err = is.gc()
+	is.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(is.blobPath(layer.Digest)); !os.IsNotExist(err) {
+		t.Error("layer of the unpinned image was kept")
+	}
+}
+
+func TestConcurrentPulls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newImageStore(logrus.New(), ImagesConfig{Dir: filepath.Join(dir, "src")})
+	shared := putBlob(t, src, tarGz(t, map[string]int{"etc/hosts": 100}))
+	index := &imgspecv1.Index{Manifests: []imgspecv1.Descriptor{
+		putImage(t, src, "a", shared, putBlob(t, src, tarGz(t, map[string]int{"etc/a": 10}))),
+		putImage(t, src, "b", shared, putBlob(t, src, tarGz(t, map[string]int{"etc/b": 10}))),
+	}}
+	if err = src.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newStorage(logrus.New(), StorageConfig{VolumeTable: filepath.Join(dir, "volumes.json")},
+		ImagesConfig{Dir: filepath.Join(dir, "images")})
+
+	// pulls copy concurrently, neither may lose the other's image
+	errs := make(chan error)
+	for _, name := range []string{"a", "b", "a"} {
+		go func(name string) {
+			_, err := s.pullImage(context.Background(), "oci:"+src.dir+":"+name, nil, nil)
+			errs <- err
+		}(name)
+	}
+	for i := 0; i < 3; i++ {
+		if err = <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	images, err := s.images.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 {
+		t.Errorf("unexpected images %v", images)
+	}
+
+	staged, _ := ioutil.ReadDir(filepath.Join(s.images.dir, stagingDir))
+	if len(staged) != 0 {
+		t.Errorf("staging layouts left behind: %d", len(staged))
+	}
+
+	target, err := ioutil.TempDir(dir, "rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.unpackImage(s.images.imagePath(images[0].name), target); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPullReusesStoredLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newImageStore(logrus.New(), ImagesConfig{Dir: filepath.Join(dir, "src")})
+	shared := putBlob(t, src, tarGz(t, map[string]int{"etc/hosts": 100}))
+	index := &imgspecv1.Index{Manifests: []imgspecv1.Descriptor{
+		putImage(t, src, "a", shared),
+		putImage(t, src, "b", shared, putBlob(t, src, tarGz(t, map[string]int{"etc/b": 10}))),
+	}}
+	if err = src.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newStorage(logrus.New(), StorageConfig{VolumeTable: filepath.Join(dir, "volumes.json")},
+		ImagesConfig{Dir: filepath.Join(dir, "images")})
+	if _, err = s.pullImage(context.Background(), "oci:"+src.dir+":a", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the layer can only come from the store now
+	if err = os.Remove(src.blobPath(shared.Digest)); err != nil {
+		t.Fatal(err)
+	}
+	imagePath, err := s.pullImage(context.Background(), "oci:"+src.dir+":b", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := ioutil.TempDir(dir, "rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.unpackImage(imagePath, target); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(target, "etc", "hosts")); err != nil {
+		t.Error(err)
+	}
+}
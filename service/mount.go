@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// mountDevice mounts the filesystem on device on a new temporary directory
+// only root can access, filesystem images in regular files are mounted
+// through a loop device. The returned function unmounts the filesystem and
+// removes the directory, it has to be called even if the caller fails
+func mountDevice(device, fsType string) (string, func() error, error) {
+	info, err := os.Stat(device)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if info.Mode().IsRegular() {
+		return mountImage(device, fsType)
+	}
+
+	if fsType == "" {
+		fsType, err = filesystemType(device)
+		if err != nil {
+			return "", nil, err
+		}
+		if fsType == "" {
+			return "", nil, fmt.Errorf("No filesystem found on %s", device)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "catapult-mount-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = unix.Mount(device, dir, fsType, 0, "")
+	if err != nil {
+		os.Remove(dir)
+		return "", nil, fmt.Errorf("Failed to mount %s on %s: %s", device, dir, err)
+	}
+
+	return dir, func() error {
+		return unmount(dir)
+	}, nil
+}
+
+// unmount unmounts dir and removes it. A busy filesystem is detached lazily
+// so it never stays mounted after a failure
+func unmount(dir string) error {
+	err := unix.Unmount(dir, 0)
+	if err == unix.EBUSY {
+		err = unix.Unmount(dir, unix.MNT_DETACH)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to unmount %s: %s", dir, err)
+	}
+
+	return os.Remove(dir)
+}
+
+// mountImage mounts a filesystem image file through a loop device, the
+// returned function also detaches the loop device
+func mountImage(image, fsType string) (string, func() error, error) {
+	loop, err := util.ExecuteCommand("losetup", "--find", "--show", image)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, unmountDevice, err := mountDevice(loop, fsType)
+	if err != nil {
+		util.ExecuteCommand("losetup", "-d", loop)
+		return "", nil, err
+	}
+
+	return dir, func() error {
+		err := unmountDevice()
+		if err != nil {
+			return err
+		}
+
+		// a lazily unmounted device is detached once it is released
+		_, err = util.ExecuteCommand("losetup", "-d", loop)
+		return err
+	}, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// requireLoopDevices skips tests that need root and loop devices
+func requireLoopDevices(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root")
+	}
+
+	for _, tool := range []string{"losetup", "mkfs.ext4", "blkid"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("needs %s", tool)
+		}
+	}
+
+	if _, err := os.Stat("/dev/loop-control"); err != nil {
+		t.Skip("needs loop devices")
+	}
+}
+
+func attachedLoops(t *testing.T, file string) string {
+	out, err := util.ExecuteCommand("losetup", "-j", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+func TestMountImage(t *testing.T) {
+	requireLoopDevices(t)
+
+	dir, err := ioutil.TempDir("", "mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	image := filepath.Join(dir, "fs.img")
+	if err = ioutil.WriteFile(image, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Truncate(image, 32*mib); err != nil {
+		t.Fatal(err)
+	}
+	if err = (mkfs{}).Format(image, "ext4"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		mountDir, unmount, err := mountDevice(image, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hello := filepath.Join(mountDir, "hello")
+		if i == 0 {
+			err = ioutil.WriteFile(hello, []byte("hello"), 0644)
+		} else {
+			_, err = os.Stat(hello)
+		}
+		if err != nil {
+			unmount()
+			t.Fatal(err)
+		}
+
+		if err = unmount(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = os.Stat(mountDir); !os.IsNotExist(err) {
+			t.Errorf("mount directory %s was not removed", mountDir)
+		}
+	}
+
+	if loops := attachedLoops(t, image); loops != "" {
+		t.Errorf("loop devices left attached: %s", loops)
+	}
+}
+
+func TestMapVolumeRollsBack(t *testing.T) {
+	requireLoopDevices(t)
+
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newStorage(logrus.New(), StorageConfig{
+		Backend:     backendLoop,
+		VolumeDir:   dir,
+		VolumeTable: filepath.Join(dir, "volumes.json"),
+	}, ImagesConfig{Dir: filepath.Join(dir, "images")})
+
+	mounts, _ := filepath.Glob(filepath.Join(os.TempDir(), "catapult-mount-*"))
+
+	// populating fails since the image does not exist
+	vol := &node.Volume{
+		VolumeID:  "rollback",
+		SizeMib:   32,
+		ImagePath: filepath.Join(dir, "missing") + ":latest",
+	}
+	if _, err = s.mapVolume(vol); err == nil {
+		t.Fatal("connecting a volume with a missing image succeeded")
+	}
+
+	if m := s.volumes.get(vol.GetVolumeID()); m != nil {
+		t.Errorf("failed volume is still connected at %s", m.Device)
+	}
+
+	if loops := attachedLoops(t, s.backends[backendFile].(*fileBackend).path(vol)); loops != "" {
+		t.Errorf("loop devices left attached: %s", loops)
+	}
+
+	left, _ := filepath.Glob(filepath.Join(os.TempDir(), "catapult-mount-*"))
+	if len(left) != len(mounts) {
+		t.Errorf("mount directories left behind: %v", left)
+	}
+}
+
+func TestMapVolumeRetriesAfterFailedPopulate(t *testing.T) {
+	requireLoopDevices(t)
+
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newStorage(logrus.New(), StorageConfig{
+		Backend:     backendLoop,
+		VolumeDir:   dir,
+		VolumeTable: filepath.Join(dir, "volumes.json"),
+	}, ImagesConfig{Dir: filepath.Join(dir, "images")})
+	files := s.backends[backendFile].(*fileBackend)
+
+	// the existing volume is kept and wiped, the one mapVolume created is
+	// deleted again
+	for _, existing := range []bool{true, false} {
+		vol := &node.Volume{
+			VolumeID:  "retry",
+			SizeMib:   32,
+			ImagePath: filepath.Join(dir, "missing") + ":latest",
+		}
+		if existing {
+			if _, _, err = files.Attach(vol); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err = s.mapVolume(vol); err == nil {
+			t.Fatal("connecting a volume with a missing image succeeded")
+		}
+
+		_, err = os.Stat(files.path(vol))
+		if existing && err != nil {
+			t.Fatalf("existing volume was removed: %s", err)
+		}
+		if !existing && !os.IsNotExist(err) {
+			t.Errorf("created volume was not removed: %v", err)
+		}
+
+		vol.Mode = node.ConnectMode_FORMAT_EMPTY
+		device, err := s.mapVolume(vol)
+		if err != nil {
+			t.Fatalf("retrying failed: %s", err)
+		}
+		if err = s.unmapVolume(context.Background(), vol.GetVolumeID(), node.EraseMode_KEEP_DATA,
+			s.logErase(vol.GetVolumeID())); err != nil {
+			t.Fatal(err)
+		}
+		if fsType, _ := filesystemType(files.path(vol)); fsType != "ext4" {
+			t.Errorf("%s has a %q filesystem after retrying", device, fsType)
+		}
+		os.Remove(files.path(vol))
+	}
+}
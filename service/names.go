@@ -0,0 +1,21 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// safeName matches the names and IDs the node puts into rbd, lvm,
+// device-mapper and file names, they can't escape the directories they are
+// joined to
+var safeName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// checkName returns an error describing what is wrong with name, kind
+// says what it names
+func checkName(kind, name string) error {
+	if !safeName.MatchString(name) {
+		return fmt.Errorf("Invalid %s %q", kind, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+func TestVolumeIDsStayInVolumeDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	volumeDir := filepath.Join(dir, "volumes")
+	s := newStorage(logrus.New(), StorageConfig{
+		Backend:     backendFile,
+		VolumeDir:   volumeDir,
+		VolumeTable: filepath.Join(dir, "volumes.json"),
+	}, ImagesConfig{Dir: filepath.Join(dir, "images")})
+
+	escaping := &node.Volume{VolumeID: "x/../../escaped", SizeMib: 1, Mode: node.ConnectMode_ATTACH_ONLY}
+	if _, err = s.mapVolume(escaping); err == nil {
+		t.Error("volume with a path as its ID was connected")
+	}
+	if _, err = s.resizeVolume(escaping, 2); err == nil {
+		t.Error("volume with a path as its ID was resized")
+	}
+
+	err = s.cloneVolume(&node.CloneRequest{
+		Snapshot: &node.VolumeSnapshot{Volume: &node.Volume{VolumeID: "1"}, Name: "snap"},
+		Target:   escaping,
+	})
+	if err == nil {
+		t.Error("volume was cloned to a path")
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*escaped*")); len(matches) != 0 {
+		t.Errorf("files created outside the volume dir: %v", matches)
+	}
+
+	for _, name := range []string{"", ".", "..", "a/b", "-rf"} {
+		if checkName("volume ID", name) == nil {
+			t.Errorf("%q was accepted", name)
+		}
+	}
+}
@@ -4,139 +4,149 @@ import (
 	"crypto/rand"
 	"fmt"
 	"net"
-	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
-
-	"github.com/PUMATeam/catapult-node/util"
+	"github.com/vishvananda/netlink"
 )
 
-// TODO make configurable
-const fcBridgeName = "fcbridge"
-
-var ips = make([]string, 0, 0)
+const defaultBridgeName = "fcbridge"
 
 type fcNetwork struct {
 	ip         string
 	bridgeIP   string
 	netmask    string
 	macAddress string
+	bridge     string
 	log        *log.Logger
 }
 
-func newNetworkService(log *log.Logger) *fcNetwork {
+func newNetworkService(log *log.Logger, bridge string) *fcNetwork {
 	return &fcNetwork{
-		log: log,
+		log:    log,
+		bridge: bridge,
 	}
 }
 
-func (fn *fcNetwork) createTapDevice(tapName string) (string, error) {
-	_, err := util.ExecuteCommand("ip", "tuntap", "add", tapName, "mode", "tap")
+// createTapDevice creates a persistent tap device and sets it up, the
+// device is removed again if it can't be set up
+func (fn *fcNetwork) createTapDevice(tapName string) error {
+	tap := &netlink.Tuntap{
+		LinkAttrs: netlink.LinkAttrs{Name: tapName},
+		Mode:      netlink.TUNTAP_MODE_TAP,
+		Flags:     netlink.TUNTAP_ONE_QUEUE | netlink.TUNTAP_NO_PI | netlink.TUNTAP_VNET_HDR,
+	}
+
+	err := netlink.LinkAdd(tap)
 	if err != nil {
-		fn.log.Error("Failed to create tap device", err)
-		return "", err
+		return fmt.Errorf("Failed to create tap device %s: %s", tapName, err)
 	}
 
-	return util.ExecuteCommand("ip", "link", "set", tapName, "up")
+	err = netlink.LinkSetUp(tap)
+	if err != nil {
+		netlink.LinkDel(tap)
+		return fmt.Errorf("Failed to set up tap device %s: %s", tapName, err)
+	}
+
+	return nil
 }
 
+// deleteDevice removes a device, devices already gone are not an error
 func (fn *fcNetwork) deleteDevice(deviceName string) error {
 	fn.log.Infof("Removing tap device %s", deviceName)
-	_, err := util.ExecuteCommand("ip", "link", "del", deviceName)
+	link, err := netlink.LinkByName(deviceName)
+	if _, ok := err.(netlink.LinkNotFoundError); ok {
+		return nil
+	}
+	if err == nil {
+		err = netlink.LinkDel(link)
+	}
 	if err != nil {
-		fn.log.Error("Failed to delete tap device", err)
+		fn.log.Errorf("Failed to delete tap device %s: %s", deviceName, err)
 		return err
 	}
 
 	return nil
 }
 
-func (fn *fcNetwork) addTapToBridge(tapName, bridgeName string) (string, error) {
-	return util.ExecuteCommand("ip", "link", "set", tapName, "master", bridgeName)
-}
-
-func (fn *fcNetwork) findAvailableIP() (string, error) {
-	// TODO handle errors
-	fn.log.Info("Looking for available IP address")
-
-	bridgeIP, err := fn.getBridge()
+func (fn *fcNetwork) addTapToBridge(tapName, bridgeName string) error {
+	bridge, err := netlink.LinkByName(bridgeName)
 	if err != nil {
-		fn.log.Errorf("Couldn't find brige %s", err)
-	}
-	if len(ips) == 0 {
-		cmd := fmt.Sprintf("nmap -v -sn -n %s -oG - | awk '/Status: Down/{print $2}'",
-			bridgeIP.String())
-		out, err := util.ExecuteCommand("bash", "-c", cmd)
-		if err != nil {
-			fn.log.Errorf("Failed to execute command: %s %s", cmd, err)
-			return "", err
-		}
+		return err
+	}
 
-		// ignore first two addresses
-		ips = strings.Split(out, "\n")[2:]
+	tap, err := netlink.LinkByName(tapName)
+	if err != nil {
+		return err
 	}
 
-	// remove selected ip
-	ips = util.RemoveFromSlice(ips, 1).([]string)
-	fn.log.Errorf("Found IP: %s", ips[1])
-	return ips[1], nil
+	return netlink.LinkSetMaster(tap, bridge)
 }
 
-func (fn *fcNetwork) getBridge() (net.Addr, error) {
-	iface, err := net.InterfaceByName(fcBridgeName)
+// getBridge returns the address of the bridge and the subnet it is in
+func (fn *fcNetwork) getBridge() (*net.IPNet, error) {
+	bridge, err := netlink.LinkByName(fn.bridge)
 	if err != nil {
+		err = fmt.Errorf("Bridge %s not available, run catapult-node network init: %s", fn.bridge, err)
 		fn.log.Error(err)
 		return nil, err
 	}
 
-	addrs, err := iface.Addrs()
+	addrs, err := netlink.AddrList(bridge, netlink.FAMILY_V4)
 	if err != nil {
 		fn.log.Error(err)
 		return nil, err
 	}
 
 	if len(addrs) == 0 {
-		return nil, fmt.Errorf("Bridge %s not available", fcBridgeName)
+		return nil, fmt.Errorf("Bridge %s has no address, run catapult-node network init", fn.bridge)
 	}
 
-	return addrs[0], nil
+	return addrs[0].IPNet, nil
 }
 
-func (fn *fcNetwork) generateMACAddress() (string, error) {
-	buf := make([]byte, 6)
-
-	_, err := rand.Read(buf)
+// neighbour reports whether the host has seen ip on the bridge, the
+// address is in use by something else than our VMs then
+func (fn *fcNetwork) neighbour(ip net.IP) bool {
+	bridge, err := netlink.LinkByName(fn.bridge)
 	if err != nil {
-		return "", err
+		return false
 	}
 
-	buf[0] |= 2
-	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x",
-		buf[0], buf[1], buf[2], buf[3], buf[4], buf[5]), nil
-}
-
-func (fn *fcNetwork) setupNetwork(tapDeviceName string) (*fcNetwork, error) {
-	bridgeAddr, err := fn.getBridge()
+	neighs, err := netlink.NeighList(bridge.Attrs().Index, netlink.FAMILY_V4)
 	if err != nil {
-		return nil, err
+		fn.log.Warnf("Failed to list the neighbours on %s: %s", fn.bridge, err)
+		return false
 	}
 
-	_, bridgeIP, err := net.ParseCIDR(bridgeAddr.String())
-	if err != nil {
-		return nil, err
+	for _, n := range neighs {
+		if n.IP.Equal(ip) && n.State&(netlink.NUD_INCOMPLETE|netlink.NUD_FAILED) == 0 {
+			return true
+		}
 	}
 
-	fn.log.Infof("Creating tap device %s...", tapDeviceName)
-	_, err = fn.createTapDevice(tapDeviceName)
+	return false
+}
+
+func (fn *fcNetwork) generateMACAddress() (string, error) {
+	buf := make([]byte, 6)
+
+	_, err := rand.Read(buf)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create tap device: %s", err)
+		return "", err
 	}
 
-	fn.log.Infof("Adding tap device %s to bridge %s", tapDeviceName, fcBridgeName)
-	_, err = fn.addTapToBridge(tapDeviceName, fcBridgeName)
+	// locally administered unicast address
+	buf[0] = buf[0]&^1 | 2
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x",
+		buf[0], buf[1], buf[2], buf[3], buf[4], buf[5]), nil
+}
 
+// setupNetwork creates the tap device of a VM on the bridge and picks its
+// address from pool, nothing is left behind if any of it fails
+func (fn *fcNetwork) setupNetwork(tapDeviceName string, pool *ipPool) (*fcNetwork, error) {
 	fn.log.Info("Looking for an IP address")
-	ip, err := fn.findAvailableIP()
+	ip, err := pool.allocate()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to find IP address: %s", err)
 	}
@@ -148,17 +158,83 @@ func (fn *fcNetwork) setupNetwork(tapDeviceName string) (*fcNetwork, error) {
 	fn.log.Info("Generating MAC address")
 	macAddress, err := fn.generateMACAddress()
 	if err != nil {
+		pool.release(ip.String())
 		return nil, fmt.Errorf("Failed to generate MAC address: %s", err)
 	}
 	fn.log.WithFields(log.Fields{
 		"MAC": macAddress,
 	}).Info("Generated MAC address")
 
+	fn.log.Infof("Creating tap device %s...", tapDeviceName)
+	err = fn.createTapDevice(tapDeviceName)
+	if err != nil {
+		pool.release(ip.String())
+		return nil, err
+	}
+
+	fn.log.Infof("Adding tap device %s to bridge %s", tapDeviceName, fn.bridge)
+	err = fn.addTapToBridge(tapDeviceName, fn.bridge)
+	if err != nil {
+		fn.deleteDevice(tapDeviceName)
+		pool.release(ip.String())
+		return nil, fmt.Errorf("Failed to add tap device %s to bridge %s: %s",
+			tapDeviceName, fn.bridge, err)
+	}
+
 	return &fcNetwork{
-		ip: ip,
-		// TODO extract and make safe
-		bridgeIP:   bridgeAddr.(*net.IPNet).IP.String(),
-		netmask:    net.IP(bridgeIP.Mask).String(),
+		ip:         ip.String(),
+		bridgeIP:   pool.gateway.String(),
+		netmask:    net.IP(pool.subnet.Mask).String(),
 		macAddress: macAddress,
 	}, nil
 }
+
+// ipPool hands out the addresses of an IPv4 subnet to VMs, skipping the
+// network address, the gateway and the broadcast address
+type ipPool struct {
+	subnet  *net.IPNet
+	gateway net.IP
+	// inUse reports addresses taken by something else than our VMs
+	inUse func(net.IP) bool
+
+	mu        sync.Mutex
+	allocated map[string]bool
+}
+
+func newIPPool(subnet *net.IPNet, gateway net.IP) *ipPool {
+	return &ipPool{
+		subnet:    subnet,
+		gateway:   gateway,
+		allocated: make(map[string]bool),
+	}
+}
+
+// allocate reserves the first free address of the subnet
+func (p *ipPool) allocate() (net.IP, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ones, bits := p.subnet.Mask.Size()
+	size := uint32(1) << uint(bits-ones)
+	for i := uint32(1); i < size-1; i++ {
+		ip := ipAdd(p.subnet.IP, i)
+		if ip.Equal(p.gateway) || p.allocated[ip.String()] {
+			continue
+		}
+
+		if p.inUse != nil && p.inUse(ip) {
+			continue
+		}
+
+		p.allocated[ip.String()] = true
+		return ip, nil
+	}
+
+	return nil, fmt.Errorf("No addresses left in %s", p.subnet)
+}
+
+func (p *ipPool) release(ip string) {
+	p.mu.Lock()
+	delete(p.allocated, ip)
+	p.mu.Unlock()
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+const (
+	ipForwardPath = "/proc/sys/net/ipv4/ip_forward"
+
+	// natTable holds the masquerading rule of the bridge network
+	natTable = "catapult_nat"
+)
+
+// InitNetwork creates the bridge VMs are plugged into, assigns it the
+// gateway address and enables forwarding and, if configured, masquerading
+// of the VMs' outbound traffic. Steps already done are skipped, so it can
+// run on every start of the node
+func InitNetwork(logger *log.Logger, cfg NetworkConfig) error {
+	bridge := bridgeName(cfg.Bridge)
+	if cfg.Bridge.Address == "" {
+		return fmt.Errorf("No address configured for bridge %s", bridge)
+	}
+
+	gateway, subnet, err := net.ParseCIDR(cfg.Bridge.Address)
+	if err != nil {
+		return fmt.Errorf("Invalid bridge address %q: %s", cfg.Bridge.Address, err)
+	}
+
+	link, err := netlink.LinkByName(bridge)
+	if _, ok := err.(netlink.LinkNotFoundError); ok {
+		logger.Infof("Creating bridge %s", bridge)
+		link = &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: bridge}}
+		err = netlink.LinkAdd(link)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to create bridge %s: %s", bridge, err)
+	}
+
+	assigned, err := hasAddress(link, gateway)
+	if err != nil {
+		return err
+	}
+
+	if !assigned {
+		logger.Infof("Assigning %s to bridge %s", cfg.Bridge.Address, bridge)
+		addr := &netlink.Addr{IPNet: &net.IPNet{IP: gateway, Mask: subnet.Mask}}
+		err = netlink.AddrAdd(link, addr)
+		if err != nil {
+			return fmt.Errorf("Failed to assign %s to bridge %s: %s", cfg.Bridge.Address, bridge, err)
+		}
+	}
+
+	err = netlink.LinkSetUp(link)
+	if err != nil {
+		return fmt.Errorf("Failed to set up bridge %s: %s", bridge, err)
+	}
+
+	logger.Info("Enabling IP forwarding")
+	err = ioutil.WriteFile(ipForwardPath, []byte("1"), 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to enable IP forwarding: %s", err)
+	}
+
+	if !cfg.Bridge.Masquerade {
+		return nil
+	}
+
+	logger.Infof("Masquerading traffic from %s", subnet)
+	return masquerade(bridge, subnet)
+}
+
+// TeardownNetwork removes the bridge and the masquerading rule created by
+// InitNetwork. IP forwarding is left enabled since other services may rely
+// on it
+func TeardownNetwork(logger *log.Logger, cfg NetworkConfig) error {
+	bridge := bridgeName(cfg.Bridge)
+	if _, err := util.ExecuteCommand("nft", "list", "table", "ip", natTable); err == nil {
+		logger.Infof("Removing nftables table %s", natTable)
+		_, err = util.ExecuteCommand("nft", "delete", "table", "ip", natTable)
+		if err != nil {
+			return err
+		}
+	}
+
+	link, err := netlink.LinkByName(bridge)
+	if _, ok := err.(netlink.LinkNotFoundError); ok {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Removing bridge %s", bridge)
+	return netlink.LinkDel(link)
+}
+
+func bridgeName(cfg BridgeConfig) string {
+	if cfg.Name == "" {
+		return defaultBridgeName
+	}
+
+	return cfg.Name
+}
+
+func hasAddress(link netlink.Link, ip net.IP) (bool, error) {
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return false, err
+	}
+
+	for _, addr := range addrs {
+		if addr.IP.Equal(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// masquerade NATs traffic from the subnet leaving through another
+// interface than the bridge. The chain is flushed first so the rule is
+// never added twice
+func masquerade(bridge string, subnet *net.IPNet) error {
+	commands := [][]string{
+		{"add", "table", "ip", natTable},
+		{"add", "chain", "ip", natTable, "postrouting",
+			"{ type nat hook postrouting priority 100 ; }"},
+		{"flush", "chain", "ip", natTable, "postrouting"},
+		{"add", "rule", "ip", natTable, "postrouting",
+			"ip", "saddr", subnet.String(), "oifname", "!=", bridge, "masquerade"},
+	}
+
+	for _, args := range commands {
+		_, err := util.ExecuteCommand("nft", args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/firecracker-microvm/firecracker-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	networkBridge = "bridge"
+	networkRouted = "routed"
+	networkCNI    = "cni"
+)
+
+// NetworkDriver connects the network interface of VMs to the host
+type NetworkDriver interface {
+	// Setup prepares the network of a VM before its VMM is started
+	Setup(vmID string) (*vmNetwork, error)
+	// Teardown releases what Setup prepared once the VM is gone
+	Teardown(n *vmNetwork) error
+}
+
+// vmNetwork describes the network interface of a VM
+type vmNetwork struct {
+	driver     string
+	tapDevice  string
+	macAddress string
+	ip         string
+	gateway    string
+	netmask    string
+	// cni is handed to the SDK, which creates the interface in a network
+	// namespace of the VM when the VMM starts
+	cni *firecracker.CNIConfiguration
+}
+
+func newNetworkDrivers(logger *log.Logger, cfg NetworkConfig) map[string]NetworkDriver {
+	return map[string]NetworkDriver{
+		networkBridge: &bridgeDriver{log: logger, bridge: bridgeName(cfg.Bridge)},
+		networkRouted: newRoutedDriver(logger, cfg.Routed),
+		networkCNI:    &cniDriver{log: logger, cfg: cfg.CNI},
+	}
+}
+
+// tapName returns the name of the tap device of a VM, fc-<last 6
+// characters of the VM UUID>
+func tapName(vmID string) string {
+	if len(vmID) > 6 {
+		vmID = vmID[len(vmID)-6:]
+	}
+
+	return fmt.Sprintf("fc-%s", vmID)
+}
+
+func (n *vmNetwork) networkInterface() firecracker.NetworkInterface {
+	if n.cni != nil {
+		return firecracker.NetworkInterface{
+			CNIConfiguration: n.cni,
+			AllowMMDS:        true,
+		}
+	}
+
+	return firecracker.NetworkInterface{
+		StaticConfiguration: &firecracker.StaticNetworkConfiguration{
+			HostDevName: n.tapDevice,
+			MacAddress:  n.macAddress,
+		},
+		AllowMMDS: true,
+	}
+}
+
+// kernelIPArg configures the guest's address through the kernel command
+// line, the SDK adds it itself for CNI networks
+func (n *vmNetwork) kernelIPArg() string {
+	if n.cni != nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" ip=%s::%s:%s::eth0:off", n.ip, n.gateway, n.netmask)
+}
+
+// updateFromCNI records the interface CNI created for the VM
+func (n *vmNetwork) updateFromCNI(m *firecracker.Machine) {
+	if n.cni == nil || len(m.Cfg.NetworkInterfaces) == 0 {
+		return
+	}
+
+	static := m.Cfg.NetworkInterfaces[0].StaticConfiguration
+	if static == nil {
+		return
+	}
+
+	n.tapDevice = static.HostDevName
+	n.macAddress = static.MacAddress
+	if ipCfg := static.IPConfiguration; ipCfg != nil {
+		n.ip = ipCfg.IPAddr.IP.String()
+		n.netmask = fmt.Sprintf("%d.%d.%d.%d",
+			ipCfg.IPAddr.Mask[0], ipCfg.IPAddr.Mask[1], ipCfg.IPAddr.Mask[2], ipCfg.IPAddr.Mask[3])
+		n.gateway = ipCfg.Gateway.String()
+	}
+}
+
+// bridgeDriver plugs tap devices into a bridge, fcbridge by default. VM
+// addresses are taken from the subnet of the bridge
+type bridgeDriver struct {
+	log    *log.Logger
+	bridge string
+
+	mu   sync.Mutex
+	pool *ipPool
+}
+
+// ipPool returns the pool of the bridge's subnet, it is replaced if the
+// bridge was given another address since
+func (b *bridgeDriver) ipPool(fn *fcNetwork) (*ipPool, error) {
+	addr, err := fn.getBridge()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subnet := &net.IPNet{IP: addr.IP.Mask(addr.Mask), Mask: addr.Mask}
+	if b.pool == nil || b.pool.subnet.String() != subnet.String() || !b.pool.gateway.Equal(addr.IP) {
+		b.pool = newIPPool(subnet, addr.IP)
+		b.pool.inUse = fn.neighbour
+	}
+
+	return b.pool, nil
+}
+
+func (b *bridgeDriver) Setup(vmID string) (*vmNetwork, error) {
+	tap := tapName(vmID)
+	fn := newNetworkService(b.log, b.bridge)
+	pool, err := b.ipPool(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := fn.setupNetwork(tap, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vmNetwork{
+		driver:     networkBridge,
+		tapDevice:  tap,
+		macAddress: network.macAddress,
+		ip:         network.ip,
+		gateway:    network.bridgeIP,
+		netmask:    network.netmask,
+	}, nil
+}
+
+func (b *bridgeDriver) Teardown(n *vmNetwork) error {
+	err := newNetworkService(b.log, b.bridge).deleteDevice(n.tapDevice)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	pool := b.pool
+	b.mu.Unlock()
+	if pool != nil {
+		pool.release(n.ip)
+	}
+
+	return nil
+}
+
+// cniDriver leaves the network of VMs to CNI plugins, which the SDK runs in
+// a network namespace of the VM
+type cniDriver struct {
+	log *log.Logger
+	cfg CNIConfig
+}
+
+func (c *cniDriver) Setup(vmID string) (*vmNetwork, error) {
+	if c.cfg.NetworkName == "" {
+		return nil, fmt.Errorf("No CNI network configured")
+	}
+
+	return &vmNetwork{
+		driver: networkCNI,
+		cni: &firecracker.CNIConfiguration{
+			NetworkName: c.cfg.NetworkName,
+			IfName:      c.cfg.IfName,
+			BinPath:     c.cfg.BinPath,
+			ConfDir:     c.cfg.ConfDir,
+			CacheDir:    c.cfg.CacheDir,
+		},
+	}, nil
+}
+
+// Teardown has nothing to do, the SDK deletes the CNI network and the
+// namespace once the VMM exits
+func (c *cniDriver) Teardown(n *vmNetwork) error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+// routedDriver gives every VM a tap device of its own and routes its
+// address to it, VMs only reach each other through the host's routing. The
+// first address of the subnet is the gateway of all VMs, it is assigned to
+// every tap device
+type routedDriver struct {
+	log  *log.Logger
+	pool *ipPool
+}
+
+func newRoutedDriver(logger *log.Logger, cfg RoutedConfig) *routedDriver {
+	r := &routedDriver{log: logger}
+	if cfg.Subnet == "" {
+		return r
+	}
+
+	_, subnet, err := net.ParseCIDR(cfg.Subnet)
+	if err != nil || subnet.IP.To4() == nil {
+		logger.Errorf("Invalid routed network subnet %q: %v", cfg.Subnet, err)
+		return r
+	}
+
+	r.pool = newIPPool(subnet, ipAdd(subnet.IP, 1))
+	return r
+}
+
+// ipAdd returns the IPv4 address n addresses after ip
+func ipAdd(ip net.IP, n uint32) net.IP {
+	next := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(next, binary.BigEndian.Uint32(ip.To4())+n)
+	return next
+}
+
+func (r *routedDriver) Setup(vmID string) (*vmNetwork, error) {
+	if r.pool == nil {
+		return nil, fmt.Errorf("No subnet configured for the routed network")
+	}
+
+	ip, err := r.pool.allocate()
+	if err != nil {
+		return nil, err
+	}
+
+	fcNetwork := newNetworkService(r.log, "")
+	macAddress, err := fcNetwork.generateMACAddress()
+	if err != nil {
+		r.pool.release(ip.String())
+		return nil, err
+	}
+
+	n := &vmNetwork{
+		driver:     networkRouted,
+		tapDevice:  tapName(vmID),
+		macAddress: macAddress,
+		ip:         ip.String(),
+		gateway:    r.pool.gateway.String(),
+		netmask:    net.IP(r.pool.subnet.Mask).String(),
+	}
+
+	r.log.Infof("Routing %s to tap device %s", n.ip, n.tapDevice)
+	err = fcNetwork.createTapDevice(n.tapDevice)
+	if err != nil {
+		r.pool.release(n.ip)
+		return nil, err
+	}
+
+	err = r.route(n)
+	if err != nil {
+		r.Teardown(n)
+		return nil, fmt.Errorf("Failed to route %s to %s: %s", n.ip, n.tapDevice, err)
+	}
+
+	return n, nil
+}
+
+// route assigns the gateway to the tap device and routes the VM's address
+// to it. Proxy ARP answers the VM's ARP requests for other VMs of the subnet
+// so their traffic goes through the host too
+func (r *routedDriver) route(n *vmNetwork) error {
+	tap, err := netlink.LinkByName(n.tapDevice)
+	if err != nil {
+		return err
+	}
+
+	err = netlink.AddrAdd(tap, &netlink.Addr{IPNet: &net.IPNet{
+		IP:   r.pool.gateway,
+		Mask: net.CIDRMask(32, 32),
+	}})
+	if err != nil {
+		return err
+	}
+
+	err = netlink.RouteAdd(&netlink.Route{
+		LinkIndex: tap.Attrs().Index,
+		Scope:     netlink.SCOPE_LINK,
+		Dst:       &net.IPNet{IP: net.ParseIP(n.ip), Mask: net.CIDRMask(32, 32)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/proxy_arp", n.tapDevice),
+		[]byte("1"), 0644)
+}
+
+// Teardown removes the tap device, its address and route go with it
+func (r *routedDriver) Teardown(n *vmNetwork) error {
+	err := newNetworkService(r.log, "").deleteDevice(n.tapDevice)
+	if err != nil {
+		return err
+	}
+
+	r.pool.release(n.ip)
+	return nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRoutedDriverAllocate(t *testing.T) {
+	r := newRoutedDriver(logrus.New(), RoutedConfig{Subnet: "10.1.0.0/29"})
+	if r.pool.gateway.String() != "10.1.0.1" {
+		t.Errorf("unexpected gateway %s", r.pool.gateway)
+	}
+
+	// .0 is the network, .1 the gateway and .7 the broadcast address
+	var got []string
+	for {
+		ip, err := r.pool.allocate()
+		if err != nil {
+			break
+		}
+		got = append(got, ip.String())
+	}
+
+	if len(got) != 5 || got[0] != "10.1.0.2" || got[4] != "10.1.0.6" {
+		t.Errorf("unexpected addresses %v", got)
+	}
+
+	r.pool.release("10.1.0.4")
+	if ip, err := r.pool.allocate(); err != nil || ip.String() != "10.1.0.4" {
+		t.Errorf("released address was not reused, got %v: %v", ip, err)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// inNetns runs f in a throwaway network namespace
+func inNetns(t *testing.T, f func()) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root")
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origin, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer origin.Close()
+
+	ns, err := netns.New()
+	if err != nil {
+		t.Skipf("can't create a network namespace: %s", err)
+	}
+	defer ns.Close()
+	defer netns.Set(origin)
+
+	f()
+}
+
+func TestBridgeDriver(t *testing.T) {
+	inNetns(t, func() {
+		cfg := NetworkConfig{Bridge: BridgeConfig{Name: "testbr0", Address: "10.2.0.1/29"}}
+		for i := 0; i < 2; i++ {
+			if err := InitNetwork(logrus.New(), cfg); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		bridge, err := netlink.LinkByName("testbr0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addrs, _ := netlink.AddrList(bridge, netlink.FAMILY_V4); len(addrs) != 1 {
+			t.Errorf("unexpected bridge addresses %v", addrs)
+		}
+
+		b := newNetworkDrivers(logrus.New(), cfg)[networkBridge]
+		first, err := b.Setup("vm-000001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := b.Setup("vm-000002")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if first.ip != "10.2.0.2" || second.ip != "10.2.0.3" || first.gateway != "10.2.0.1" ||
+			first.netmask != "255.255.255.248" {
+			t.Errorf("unexpected addresses %s and %s via %s/%s",
+				first.ip, second.ip, first.gateway, first.netmask)
+		}
+
+		tap, err := netlink.LinkByName(first.tapDevice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tap.Attrs().MasterIndex != bridge.Attrs().Index || tap.Attrs().Flags&net.FlagUp == 0 {
+			t.Errorf("tap device is not up on the bridge: %+v", tap.Attrs())
+		}
+
+		if err = b.Teardown(first); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = netlink.LinkByName(first.tapDevice); err == nil {
+			t.Errorf("tap device %s was not removed", first.tapDevice)
+		}
+
+		third, err := b.Setup("vm-000003")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if third.ip != first.ip {
+			t.Errorf("released address %s was not reused, got %s", first.ip, third.ip)
+		}
+
+		b.Teardown(second)
+		b.Teardown(third)
+		if err = TeardownNetwork(logrus.New(), cfg); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = netlink.LinkByName("testbr0"); err == nil {
+			t.Error("bridge was not removed")
+		}
+	})
+}
+
+func TestBridgeDriverCleansUp(t *testing.T) {
+	inNetns(t, func() {
+		// a bridge that isn't one, tap devices can't be added to it
+		fake := &netlink.Tuntap{LinkAttrs: netlink.LinkAttrs{Name: "testbr0"}, Mode: netlink.TUNTAP_MODE_TAP}
+		if err := netlink.LinkAdd(fake); err != nil {
+			t.Fatal(err)
+		}
+		addr, _ := netlink.ParseAddr("10.2.0.1/24")
+		if err := netlink.AddrAdd(fake, addr); err != nil {
+			t.Fatal(err)
+		}
+
+		b := &bridgeDriver{log: logrus.New(), bridge: "testbr0"}
+		if _, err := b.Setup("vm-000001"); err == nil {
+			t.Fatal("setting up a network on a tap device succeeded")
+		}
+
+		if _, err := netlink.LinkByName(tapName("vm-000001")); err == nil {
+			t.Error("tap device was left behind")
+		}
+		if len(b.pool.allocated) != 0 {
+			t.Errorf("addresses left allocated: %v", b.pool.allocated)
+		}
+	})
+}
+
+func TestRoutedDriver(t *testing.T) {
+	inNetns(t, func() {
+		r := newRoutedDriver(logrus.New(), RoutedConfig{Subnet: "10.3.0.0/24"})
+		n, err := r.Setup("vm-000001")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tap, err := netlink.LinkByName(n.tapDevice)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		routes, err := netlink.RouteList(tap, netlink.FAMILY_V4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var routed bool
+		for _, route := range routes {
+			routed = routed || (route.Dst != nil && route.Dst.String() == n.ip+"/32")
+		}
+		if !routed {
+			t.Errorf("%s is not routed to %s: %v", n.ip, n.tapDevice, routes)
+		}
+
+		if err = r.Teardown(n); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = netlink.LinkByName(n.tapDevice); err == nil {
+			t.Errorf("tap device %s was not removed", n.tapDevice)
+		}
+		if len(r.pool.allocated) != 0 {
+			t.Errorf("addresses left allocated: %v", r.pool.allocated)
+		}
+	})
+}
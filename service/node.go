@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
-
-	"github.com/firecracker-microvm/firecracker-go-sdk"
+	"os"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -14,61 +16,230 @@ import (
 )
 
 type NodeService struct {
-	Machines map[string]*firecracker.Machine
-	log      *logrus.Logger
-	storage  *storage
+	vms            map[string]*vm
+	cids           map[uint32]string
+	mu             sync.RWMutex
+	log            *logrus.Logger
+	storage        *storage
+	operations     *operations
+	networks       map[string]NetworkDriver
+	defaultNetwork string
+	firewall       *firewall
+	signals        sync.Once
 }
 
-func NewNodeService(log *logrus.Logger, machines map[string]*firecracker.Machine) *NodeService {
+func NewNodeService(log *logrus.Logger, cfg Config) *NodeService {
+	defaultNetwork := cfg.Network.Driver
+	if defaultNetwork == "" {
+		defaultNetwork = networkBridge
+	}
+
 	return &NodeService{
-		Machines: machines,
-		log:      log,
-		storage:  &storage{log: log},
+		vms:            make(map[string]*vm),
+		cids:           make(map[uint32]string),
+		log:            log,
+		storage:        newStorage(log, cfg.Storage, cfg.Images),
+		operations:     newOperations(),
+		networks:       newNetworkDrivers(log, cfg.Network),
+		defaultNetwork: defaultNetwork,
+		firewall:       newFirewall(log, cfg.Network),
+	}
+}
+
+// network returns the named network driver, or the node's default one
+func (ns *NodeService) network(name string) (NetworkDriver, error) {
+	if name == "" {
+		name = ns.defaultNetwork
+	}
+
+	d, ok := ns.networks[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown network driver %q", name)
+	}
+
+	return d, nil
+}
+
+func (ns *NodeService) getVM(vmID string) (*vm, error) {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	v, ok := ns.vms[vmID]
+	if !ok {
+		return nil, fmt.Errorf("VM %s not found", vmID)
+	}
+
+	return v, nil
+}
+
+// allocateCID reserves the lowest vsock CID not used by another VM
+func (ns *NodeService) allocateCID(vmID string) uint32 {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	cid := uint32(firstGuestCID)
+	for {
+		if _, ok := ns.cids[cid]; !ok {
+			ns.cids[cid] = vmID
+			return cid
+		}
+		cid++
 	}
 }
 
+func (ns *NodeService) releaseCID(cid uint32) {
+	ns.mu.Lock()
+	delete(ns.cids, cid)
+	ns.mu.Unlock()
+}
+
 // StartVM starts a firecracker VM with the provided configuration
 func (ns *NodeService) StartVM(ctx context.Context, cfg *node.VmConfig) (*node.VmResponse, error) {
 	ns.log.Info("Starting VM ", cfg.GetVmID().GetValue())
 	vmID := cfg.GetVmID().GetValue()
 
-	fcNetwork := newNetworkService(ns.log)
-	// tap device name would be fc-<last 6 characters of VM UUID>
+	err := checkName("VM ID", vmID)
+	if err == nil {
+		err = validateProbe(cfg.GetReadinessProbe())
+	}
+	if err != nil {
+		ns.log.Error(err)
+		return &node.VmResponse{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	if overlay := cfg.GetRootfsOverlay(); overlay != nil {
+		rootfs, err := ns.storage.overlays.create(overlay.GetBaseImage(), vmID)
+		if err != nil {
+			err = fmt.Errorf("Failed to create overlay of %s: %s", overlay.GetBaseImage(), err)
+			ns.log.Error(err)
+			return &node.VmResponse{
+				Status: node.Status_FAILED,
+			}, err
+		}
+		cfg.RootFileSystem = rootfs
+	}
+
+	// the volume can't be disconnected while the VM starts, it is in use
+	// by the VM afterwards
+	claimed, err := ns.storage.volumes.claim(cfg.GetRootFileSystem(), vmID)
+	if err != nil {
+		ns.log.Error(err)
+		ns.removeOverlay(cfg)
+		return &node.VmResponse{
+			Status: node.Status_FAILED,
+		}, err
+	}
+	if claimed != "" {
+		defer ns.storage.volumes.unreserve(claimed)
+	}
+
 	ns.log.Infof("Setting up network...")
-	tapDeviceName := fmt.Sprintf("%s-%s", "fc", vmID[len(vmID)-6:])
-	network, err := fcNetwork.setupNetwork(tapDeviceName)
+	driver, err := ns.network(cfg.GetNetwork())
+	var network *vmNetwork
+	if err == nil {
+		network, err = driver.Setup(vmID)
+	}
 
 	if err != nil {
 		ns.log.Error(err)
+		ns.removeOverlay(cfg)
 		return &node.VmResponse{
 			Status: node.Status_FAILED,
 		}, err
 	}
 
-	cfg.Address = network.ip
+	err = ns.firewall.apply(network, cfg.GetFirewall())
+	if err != nil {
+		err = fmt.Errorf("Failed to apply the firewall of VM %s: %s", vmID, err)
+		ns.log.Error(err)
+		ns.teardownNetwork(network)
+		ns.removeOverlay(cfg)
+		return &node.VmResponse{
+			Status: node.Status_FAILED,
+		}, err
+	}
 
 	fch := &fc{
-		vmID:          cfg.GetVmID().GetValue(),
-		tapDeviceName: tapDeviceName,
-		macAddress:    network.macAddress,
-		ipAddress:     network.ip,
-		bridgeIP:      network.bridgeIP,
-		netmask:       network.netmask,
+		vmID:    cfg.GetVmID().GetValue(),
+		network: network,
+	}
+
+	if cfg.GetEnableVsock() {
+		fch.vsockCID = ns.allocateCID(vmID)
+		cfg.VsockCID = fch.vsockCID
+		ns.log.Infof("Allocated vsock CID %d for VM %s", fch.vsockCID, vmID)
 	}
 
 	ns.log.Infof("Starting VM ")
 	m, err := fch.runVMM(context.Background(), cfg, ns.log)
 	if err != nil {
+		if fch.vsockCID != 0 {
+			ns.releaseCID(fch.vsockCID)
+		}
+		ns.removeOverlay(cfg)
+		ns.teardownNetwork(network)
 		return &node.VmResponse{
 			Status: node.Status_FAILED,
 		}, err
 	}
 
-	ns.Machines[cfg.GetVmID().GetValue()] = m
+	// CNI networks only know the address once the VMM started
+	cfg.Address = network.ip
+
+	probe := cfg.GetReadinessProbe()
+	waitReady := probe.GetType() != node.ProbeType_NONE
+
+	v := &vm{
+		machine: m,
+		fc:      fch,
+		cfg:     cfg,
+		state:   node.VmState_RUNNING,
+		stopped: make(chan struct{}),
+	}
+	if waitReady {
+		v.state = node.VmState_STARTING
+	}
+
+	ns.mu.Lock()
+	ns.vms[vmID] = v
+	ns.mu.Unlock()
+	ns.signals.Do(ns.installSignalHandlers)
 
 	go fch.readPipe(ns.log, "log")
 	go fch.readPipe(ns.log, "metrics")
 
+	if waitReady {
+		err = fch.waitReady(ctx, probe, ns.log)
+		if err != nil {
+			err = fmt.Errorf("VM %s did not become ready: %s", vmID, err)
+			ns.log.Error(err)
+			m.StopVMM()
+			ns.removeVM(v)
+			return &node.VmResponse{
+				Status: node.Status_FAILED,
+			}, err
+		}
+
+		v.Lock()
+		stopped := v.isStopped()
+		if !stopped {
+			v.state = node.VmState_RUNNING
+		}
+		v.Unlock()
+
+		if stopped {
+			err = fmt.Errorf("VM %s was stopped while waiting for it to become ready", vmID)
+			ns.log.Error(err)
+			return &node.VmResponse{
+				Status: node.Status_FAILED,
+			}, err
+		}
+	}
+
+	go ns.supervise(v)
+
 	return &node.VmResponse{
 		Status: node.Status_SUCCESS,
 		Config: cfg,
@@ -77,14 +248,23 @@ func (ns *NodeService) StartVM(ctx context.Context, cfg *node.VmConfig) (*node.V
 
 func (ns *NodeService) StopVM(ctx context.Context, uuid *node.UUID) (*node.Response, error) {
 	ns.log.Debug("StopVM called on VM ", uuid.GetValue())
-	v, ok := ns.Machines[uuid.GetValue()]
-	if !ok {
-		ns.log.Errorf("VM %s not found", uuid.GetValue())
+	v, err := ns.getVM(uuid.GetValue())
+	if err != nil {
+		ns.log.Error(err)
 		return &node.Response{
 			Status: node.Status_FAILED,
-		}, fmt.Errorf("VM %s not found", uuid.GetValue())
+		}, err
+	}
+
+	v.Lock()
+	if !v.isStopped() {
+		close(v.stopped)
+	}
+	err = v.machine.StopVMM()
+	if err == nil {
+		v.state = node.VmState_STOPPED
 	}
-	err := v.StopVMM()
+	v.Unlock()
 	if err != nil {
 		ns.log.Errorf("Failed to stop VM %s", uuid.GetValue())
 		return &node.Response{
@@ -93,30 +273,352 @@ func (ns *NodeService) StopVM(ctx context.Context, uuid *node.UUID) (*node.Respo
 	}
 
 	ns.log.Infof("Stopped VM %s", uuid.GetValue())
+	ns.removeVM(v)
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// DeleteVM stops the VM if it is still running and disconnects the volumes
+// it booted from
+func (ns *NodeService) DeleteVM(ctx context.Context, uuid *node.UUID) (*node.Response, error) {
+	ns.log.Debug("DeleteVM called on VM ", uuid.GetValue())
+	if _, err := ns.getVM(uuid.GetValue()); err == nil {
+		_, err = ns.StopVM(ctx, uuid)
+		if err != nil {
+			return &node.Response{
+				Status: node.Status_FAILED,
+			}, err
+		}
+	}
+
+	var err error
+	for _, m := range ns.storage.volumes.ownedBy(uuid.GetValue()) {
+		unmapErr := ns.releaseOwnedVolume(ctx, m.VolumeID)
+		if unmapErr != nil {
+			ns.log.Errorf("Failed to disconnect volume %s of VM %s: %s",
+				m.VolumeID, uuid.GetValue(), unmapErr)
+			err = unmapErr
+		}
+	}
+
+	if err != nil {
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// releaseOwnedVolume disconnects a volume of a deleted VM unless it is
+// already being disconnected
+func (ns *NodeService) releaseOwnedVolume(ctx context.Context, volumeID string) error {
+	_, err := ns.storage.volumes.reserveConnected(volumeID)
+	if err != nil {
+		return err
+	}
+	defer ns.storage.volumes.unreserve(volumeID)
+
+	return ns.storage.unmapVolume(ctx, volumeID, node.EraseMode_KEEP_DATA, ns.storage.logErase(volumeID))
+}
+
+// removeVM releases the resources held by a stopped VM and forgets it
+func (ns *NodeService) removeVM(v *vm) {
+	// StopVM and a failing StartVM or supervisor may both get here, the
+	// resources are only released once
+	ns.mu.Lock()
+	if v.removed || ns.vms[v.fc.vmID] != v {
+		ns.mu.Unlock()
+		return
+	}
+	v.removed = true
+	ns.mu.Unlock()
+
 	ns.log.Info("Cleaning up...")
+	ns.teardownNetwork(v.fc.network)
+	v.fc.closeConsole()
 
-	vmID := uuid.GetValue()
-	fcNetwork := newNetworkService(ns.log)
-	fcNetwork.deleteDevice(fmt.Sprintf("%s-%s", "fc", vmID[len(vmID)-6:]))
+	ns.mu.Lock()
+	delete(ns.vms, v.fc.vmID)
+	delete(ns.cids, v.fc.vsockCID)
+	ns.mu.Unlock()
+
+	ns.removeOverlay(v.cfg)
+}
+
+// teardownNetwork releases the network of a VM with the driver that set it
+// up
+func (ns *NodeService) teardownNetwork(n *vmNetwork) {
+	err := ns.firewall.remove(n)
+	if err != nil {
+		ns.log.Errorf("Failed to remove the firewall of network device %s: %s", n.tapDevice, err)
+	}
+
+	driver, err := ns.network(n.driver)
+	if err == nil {
+		err = driver.Teardown(n)
+	}
+
+	if err != nil {
+		ns.log.Errorf("Failed to tear down network device %s: %s", n.tapDevice, err)
+	}
+}
+
+// removeOverlay releases the root filesystem overlay of a VM, if it has one
+func (ns *NodeService) removeOverlay(cfg *node.VmConfig) {
+	overlay := cfg.GetRootfsOverlay()
+	if overlay == nil {
+		return
+	}
+
+	err := ns.storage.overlays.remove(cfg.GetVmID().GetValue(), overlay.GetKeep())
+	if err != nil {
+		ns.log.Errorf("Failed to remove overlay of VM %s: %s", cfg.GetVmID().GetValue(), err)
+	}
+}
+
+// vmUsingDevice returns the ID of the VM using device as a drive, if any
+func (ns *NodeService) vmUsingDevice(device string) string {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	for id, v := range ns.vms {
+		if v.cfg.GetRootFileSystem() == device {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// PauseVM pauses a running VM, keeping its memory and devices intact
+func (ns *NodeService) PauseVM(ctx context.Context, uuid *node.UUID) (*node.Response, error) {
+	ns.log.Debug("PauseVM called on VM ", uuid.GetValue())
+	return ns.changeVMState(ctx, uuid.GetValue(),
+		node.VmState_RUNNING, node.VmState_PAUSED, vmStatePaused)
+}
+
+// ResumeVM resumes a paused VM
+func (ns *NodeService) ResumeVM(ctx context.Context, uuid *node.UUID) (*node.Response, error) {
+	ns.log.Debug("ResumeVM called on VM ", uuid.GetValue())
+	return ns.changeVMState(ctx, uuid.GetValue(),
+		node.VmState_PAUSED, node.VmState_RUNNING, vmStateResumed)
+}
+
+func (ns *NodeService) changeVMState(ctx context.Context,
+	vmID string,
+	from, to node.VmState,
+	fcState string) (*node.Response, error) {
+	v, err := ns.getVM(vmID)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	if v.state != from {
+		err = fmt.Errorf("VM %s is %s, expected %s", vmID, v.state, from)
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	err = newFcClient(v.fc.socketPath()).patchVMState(ctx, fcState)
+	if err != nil {
+		ns.log.Errorf("Failed to change state of VM %s to %s: %s", vmID, fcState, err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	v.state = to
+	ns.log.Infof("VM %s is %s", vmID, to)
 
 	return &node.Response{
-		Status: node.Status_FAILED,
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// SetBalloon inflates or deflates the balloon of a VM to the requested size,
+// reclaiming guest memory for the host
+func (ns *NodeService) SetBalloon(ctx context.Context, target *node.BalloonTarget) (*node.Response, error) {
+	vmID := target.GetVmID().GetValue()
+	ns.log.Debugf("SetBalloon called on VM %s with target %d MiB", vmID, target.GetTargetMib())
+	v, err := ns.getVM(vmID)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	if v.cfg.GetBalloon() == nil {
+		err = fmt.Errorf("VM %s has no balloon device", vmID)
+	} else if target.GetTargetMib() < 0 || target.GetTargetMib() > v.cfg.GetMemory() {
+		err = fmt.Errorf("Balloon target %d MiB is out of range for VM %s with %d MiB",
+			target.GetTargetMib(), vmID, v.cfg.GetMemory())
+	}
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	err = newFcClient(v.fc.socketPath()).patchBalloon(ctx, target.GetTargetMib())
+	if err != nil {
+		ns.log.Errorf("Failed to update balloon of VM %s: %s", vmID, err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	v.cfg.Balloon.AmountMib = target.GetTargetMib()
+	ns.log.Infof("Balloon of VM %s set to %d MiB", vmID, target.GetTargetMib())
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// GetBalloonStats returns the balloon statistics reported by the guest, the
+// VM must be started with a non-zero statistics polling interval
+func (ns *NodeService) GetBalloonStats(ctx context.Context, uuid *node.UUID) (*node.BalloonStats, error) {
+	ns.log.Debug("GetBalloonStats called on VM ", uuid.GetValue())
+	v, err := ns.getVM(uuid.GetValue())
+	if err != nil {
+		ns.log.Error(err)
+		return &node.BalloonStats{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	stats, err := newFcClient(v.fc.socketPath()).getBalloonStats(ctx)
+	if err != nil {
+		ns.log.Errorf("Failed to get balloon statistics of VM %s: %s", uuid.GetValue(), err)
+		return &node.BalloonStats{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.BalloonStats{
+		Status:          node.Status_SUCCESS,
+		TargetMib:       stats.TargetMib,
+		ActualMib:       stats.ActualMib,
+		TargetPages:     stats.TargetPages,
+		ActualPages:     stats.ActualPages,
+		SwapIn:          stats.SwapIn,
+		SwapOut:         stats.SwapOut,
+		MajorFaults:     stats.MajorFaults,
+		MinorFaults:     stats.MinorFaults,
+		FreeMemory:      stats.FreeMemory,
+		TotalMemory:     stats.TotalMemory,
+		AvailableMemory: stats.AvailableMemory,
+		DiskCaches:      stats.DiskCaches,
+	}, nil
+}
+
+// GuestPing checks that the agent inside the guest answers over vsock
+func (ns *NodeService) GuestPing(ctx context.Context, uuid *node.UUID) (*node.Response, error) {
+	ns.log.Debug("GuestPing called on VM ", uuid.GetValue())
+	agent, err := ns.guestAgent(uuid.GetValue())
+	if err == nil {
+		err = agent.ping(ctx)
+	}
+
+	if err != nil {
+		ns.log.Errorf("Failed to ping agent of VM %s: %s", uuid.GetValue(), err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// ExecInGuest runs a command inside the guest through the agent
+func (ns *NodeService) ExecInGuest(ctx context.Context, cmd *node.GuestCommand) (*node.GuestCommandResult, error) {
+	vmID := cmd.GetVmID().GetValue()
+	ns.log.Debugf("ExecInGuest called on VM %s with command %s %v", vmID, cmd.GetCommand(), cmd.GetArgs())
+	agent, err := ns.guestAgent(vmID)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.GuestCommandResult{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	if cmd.GetTimeoutSeconds() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cmd.GetTimeoutSeconds())*time.Second)
+		defer cancel()
+	}
+
+	resp, err := agent.exec(ctx, cmd.GetCommand(), cmd.GetArgs())
+	if err != nil {
+		ns.log.Errorf("Failed to execute %s in VM %s: %s", cmd.GetCommand(), vmID, err)
+		return &node.GuestCommandResult{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.GuestCommandResult{
+		Status:   node.Status_SUCCESS,
+		ExitCode: resp.ExitCode,
+		Stdout:   resp.Stdout,
+		Stderr:   resp.Stderr,
 	}, nil
 }
 
+func (ns *NodeService) guestAgent(vmID string) (*guestAgent, error) {
+	v, err := ns.getVM(vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.fc.vsockCID == 0 {
+		return nil, fmt.Errorf("VM %s was started without vsock", vmID)
+	}
+
+	return newGuestAgent(v.fc.vsockPath()), nil
+}
+
 func (ns *NodeService) ListVMs(context.Context, *empty.Empty) (*node.VmList, error) {
 	ns.log.Debug("ListVMs called")
+	ns.mu.RLock()
+	ids := make([]string, 0, len(ns.vms))
+	for id := range ns.vms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
 	vmList := new(node.VmList)
-	uuid := &node.UUID{
-		Value: "poop",
+	for _, id := range ids {
+		info := ns.vms[id].info()
+		vmList.VmID = append(vmList.VmID, info.GetVmID())
+		vmList.Vms = append(vmList.Vms, info)
 	}
+	ns.mu.RUnlock()
 
-	vmList.VmID = []*node.UUID{uuid}
 	return vmList, nil
 }
 
 func (ns *NodeService) CreateDrive(ctx context.Context, img *node.ImageName) (*node.DriveResponse, error) {
-	path, err := ns.storage.pullImage(ctx, img.GetName())
+	path, err := ns.storage.pullImage(ctx, img.GetName(), img.GetCredentials(), nil)
 	if err != nil {
 		return &node.DriveResponse{
 			Status: node.Status_FAILED,
@@ -125,7 +627,8 @@ func (ns *NodeService) CreateDrive(ctx context.Context, img *node.ImageName) (*n
 		}, err
 	}
 
-	size, err := ns.storage.dirSize(path)
+	_, storedName := splitImagePath(path)
+	sizes, err := ns.storage.images.imageSizes(storedName, ns.storage.headroom)
 	if err != nil {
 		ns.log.Error(err)
 		return &node.DriveResponse{
@@ -136,14 +639,174 @@ func (ns *NodeService) CreateDrive(ctx context.Context, img *node.ImageName) (*n
 	}
 
 	return &node.DriveResponse{
+		Status:          node.Status_SUCCESS,
+		Size:            sizes.compressed,
+		Path:            path,
+		UnpackedSize:    sizes.unpacked,
+		RequiredSizeMib: sizes.requiredMib,
+	}, err
+}
+
+// ListImages lists the images in the node's image store
+func (ns *NodeService) ListImages(context.Context, *empty.Empty) (*node.ImageList, error) {
+	ns.log.Debug("ListImages called")
+	ns.storage.images.Lock()
+	images, err := ns.storage.images.list()
+	ns.storage.images.Unlock()
+	if err != nil {
+		ns.log.Error(err)
+		return nil, err
+	}
+
+	usage, err := ns.storage.images.usage()
+	if err != nil {
+		ns.log.Error(err)
+		return nil, err
+	}
+
+	imageList := &node.ImageList{
+		Usage: usage,
+		Quota: ns.storage.images.quota,
+	}
+	for _, img := range images {
+		imageList.Images = append(imageList.Images, &node.ImageInfo{
+			Name:   img.name,
+			Digest: img.digest,
+			Size:   img.size,
+		})
+	}
+
+	return imageList, nil
+}
+
+// RemoveImage removes an image from the image store along with the layers
+// no other image uses
+func (ns *NodeService) RemoveImage(ctx context.Context, img *node.ImageName) (*node.Response, error) {
+	ns.log.Debug("RemoveImage called with image ", img.GetName())
+	err := ns.storage.images.remove(img.GetName())
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// PullImage starts pulling an image in the background and returns the
+// operation tracking it, the pull is not bound to the request's context
+func (ns *NodeService) PullImage(ctx context.Context, img *node.ImageName) (*node.Operation, error) {
+	ns.log.Debug("PullImage called with image ", img.GetName())
+	pullCtx, cancel := context.WithCancel(context.Background())
+	o := ns.operations.start(&node.Operation{Image: img.GetName()}, cancel)
+	go ns.storage.runPull(pullCtx, o, img)
+
+	op, _ := o.snapshot()
+	return op, nil
+}
+
+// GetOperation returns the current state of an operation
+func (ns *NodeService) GetOperation(ctx context.Context, id *node.UUID) (*node.Operation, error) {
+	o, err := ns.operations.get(id.GetValue())
+	if err != nil {
+		ns.log.Error(err)
+		return nil, err
+	}
+
+	op, _ := o.snapshot()
+	return op, nil
+}
+
+// WatchOperation streams the state of an operation on every change until it
+// is done
+func (ns *NodeService) WatchOperation(id *node.UUID, stream node.Node_WatchOperationServer) error {
+	o, err := ns.operations.get(id.GetValue())
+	if err != nil {
+		ns.log.Error(err)
+		return err
+	}
+
+	for {
+		op, changed := o.snapshot()
+		err = stream.Send(op)
+		if err != nil || operationDone(op) {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// CancelOperation cancels a running operation
+func (ns *NodeService) CancelOperation(ctx context.Context, id *node.UUID) (*node.Response, error) {
+	ns.log.Debug("CancelOperation called on operation ", id.GetValue())
+	o, err := ns.operations.get(id.GetValue())
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	op, _ := o.snapshot()
+	if operationDone(op) {
+		err = fmt.Errorf("Operation %s is already %s", id.GetValue(), op.GetState())
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	o.cancel()
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// BuildRootfs builds a raw filesystem image from a container image, the
+// returned path can be used as a VM's root file system as is
+func (ns *NodeService) BuildRootfs(ctx context.Context, req *node.RootfsRequest) (*node.RootfsResponse, error) {
+	ns.log.Debug("BuildRootfs called with image ", req.GetImage())
+	path, err := ns.storage.buildRootfs(ctx, &rootfsRequest{
+		image:       req.GetImage(),
+		credentials: req.GetCredentials(),
+		sizeMib:     req.GetSizeMib(),
+		fsType:      req.GetFsType(),
+		init:        req.GetInit(),
+		files:       req.GetFiles(),
+	})
+	if err != nil {
+		return &node.RootfsResponse{
+			Status: node.Status_FAILED,
+			Size:   -1,
+		}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.RootfsResponse{
+			Status: node.Status_FAILED,
+			Size:   -1,
+		}, err
+	}
+
+	return &node.RootfsResponse{
 		Status: node.Status_SUCCESS,
-		Size:   size,
 		Path:   path,
-	}, err
+		Size:   info.Size(),
+	}, nil
 }
 
 func (ns *NodeService) ConnectVolume(ctx context.Context, vol *node.Volume) (*node.ConnectResponse, error) {
-	drive, err := ns.storage.mapVolume(vol.GetVolumeID(), vol.GetPoolName(), vol.GetImagePath())
+	drive, err := ns.storage.mapVolume(vol)
 	if err != nil {
 		return &node.ConnectResponse{
 			Path:   "",
@@ -156,3 +819,185 @@ func (ns *NodeService) ConnectVolume(ctx context.Context, vol *node.Volume) (*no
 		Path:   drive,
 	}, err
 }
+
+// DisconnectVolume detaches a volume connected with ConnectVolume, volumes
+// used by a VM can't be disconnected. The volume is erased first if asked to
+// now or when it was connected
+func (ns *NodeService) DisconnectVolume(ctx context.Context, vol *node.Volume) (*node.Response, error) {
+	ns.log.Debug("DisconnectVolume called on volume ", vol.GetVolumeID())
+	m, err := ns.releasableVolume(vol)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+	defer ns.storage.volumes.unreserve(m.VolumeID)
+
+	err = ns.storage.unmapVolume(ctx, m.VolumeID, vol.GetErase(), ns.storage.logErase(m.VolumeID))
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// ReleaseVolume erases and disconnects a volume in the background, the
+// returned operation reports the progress of the erasure
+func (ns *NodeService) ReleaseVolume(ctx context.Context, vol *node.Volume) (*node.Operation, error) {
+	ns.log.Debug("ReleaseVolume called on volume ", vol.GetVolumeID())
+	_, err := ns.releasableVolume(vol)
+	if err != nil {
+		ns.log.Error(err)
+		return nil, err
+	}
+
+	releaseCtx, cancel := context.WithCancel(context.Background())
+	o := ns.operations.start(&node.Operation{VolumeID: vol.GetVolumeID()}, cancel)
+	go ns.storage.runRelease(releaseCtx, o, vol)
+
+	op, _ := o.snapshot()
+	return op, nil
+}
+
+// releasableVolume reserves a connected volume no VM uses for being
+// disconnected and returns its mapping, the caller unreserves it once done
+func (ns *NodeService) releasableVolume(vol *node.Volume) (*volumeMapping, error) {
+	m, err := ns.storage.volumes.reserveConnected(vol.GetVolumeID())
+	if err != nil {
+		return nil, err
+	}
+
+	if vmID := ns.vmUsingDevice(m.Device); vmID != "" {
+		ns.storage.volumes.unreserve(vol.GetVolumeID())
+		return nil, fmt.Errorf("Volume %s is attached to VM %s", vol.GetVolumeID(), vmID)
+	}
+
+	return m, nil
+}
+
+// SnapshotVolume takes a snapshot of a volume with the volume's backend
+func (ns *NodeService) SnapshotVolume(ctx context.Context, snap *node.VolumeSnapshot) (*node.Response, error) {
+	ns.log.Debugf("SnapshotVolume called on volume %s with snapshot %s",
+		snap.GetVolume().GetVolumeID(), snap.GetName())
+	err := ns.storage.snapshotVolume(snap)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// DeleteVolumeSnapshot removes a snapshot taken with SnapshotVolume
+func (ns *NodeService) DeleteVolumeSnapshot(ctx context.Context, snap *node.VolumeSnapshot) (*node.Response, error) {
+	ns.log.Debugf("DeleteVolumeSnapshot called on volume %s with snapshot %s",
+		snap.GetVolume().GetVolumeID(), snap.GetName())
+	err := ns.storage.deleteSnapshot(snap)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// CloneVolume creates a new volume from a snapshot, the clone can be
+// connected with ConnectVolume in ATTACH_ONLY mode
+func (ns *NodeService) CloneVolume(ctx context.Context, req *node.CloneRequest) (*node.Response, error) {
+	ns.log.Debugf("CloneVolume called on snapshot %s of volume %s",
+		req.GetSnapshot().GetName(), req.GetSnapshot().GetVolume().GetVolumeID())
+	err := ns.storage.cloneVolume(req)
+	if err != nil {
+		ns.log.Error(err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// ResizeVolume grows a volume. The filesystem of a connected volume is grown
+// too, through the guest agent if a VM is using it
+func (ns *NodeService) ResizeVolume(ctx context.Context, req *node.ResizeRequest) (*node.Response, error) {
+	volumeID := req.GetVolume().GetVolumeID()
+	ns.log.Debugf("ResizeVolume called on volume %s with size %d MiB", volumeID, req.GetSizeMib())
+	device, err := ns.storage.resizeVolume(req.GetVolume(), req.GetSizeMib())
+	if err == nil && device != "" {
+		if vmID := ns.vmUsingDevice(device); vmID != "" {
+			err = ns.growGuestFilesystem(ctx, vmID, device)
+		} else {
+			err = ns.storage.growFilesystem(device)
+		}
+	}
+
+	if err != nil {
+		ns.log.Errorf("Failed to resize volume %s: %s", volumeID, err)
+		return &node.Response{
+			Status: node.Status_FAILED,
+		}, err
+	}
+
+	ns.log.Infof("Resized volume %s to %d MiB", volumeID, req.GetSizeMib())
+	return &node.Response{
+		Status: node.Status_SUCCESS,
+	}, nil
+}
+
+// growGuestFilesystem tells firecracker the root drive of the VM grew and
+// has the guest agent grow the filesystem on it
+func (ns *NodeService) growGuestFilesystem(ctx context.Context, vmID, device string) error {
+	v, err := ns.getVM(vmID)
+	if err != nil {
+		return err
+	}
+
+	err = newFcClient(v.fc.socketPath()).rescanDrive(ctx, rootDriveID)
+	if err != nil {
+		return fmt.Errorf("Failed to rescan the root drive of VM %s: %s", vmID, err)
+	}
+
+	fsType, err := filesystemType(device)
+	if err != nil {
+		return err
+	}
+
+	command, args, err := guestGrowCommand(fsType)
+	if err != nil {
+		return err
+	}
+
+	agent, err := ns.guestAgent(vmID)
+	if err != nil {
+		ns.log.Warnf("Can't grow the filesystem of VM %s, it has to be grown from the guest: %s",
+			vmID, err)
+		return nil
+	}
+
+	resp, err := agent.exec(ctx, command, args)
+	if err != nil {
+		return err
+	}
+
+	if resp.ExitCode != 0 {
+		return fmt.Errorf("%s in VM %s exited with %d: %s", command, vmID, resp.ExitCode, resp.Stderr)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containers/image/types"
+	"github.com/golang/protobuf/proto"
+	uuid "github.com/satori/go.uuid"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+const (
+	// operationRetention is how long finished operations can still be
+	// queried
+	operationRetention = time.Hour
+
+	progressInterval = 500 * time.Millisecond
+)
+
+// operation is an image pull running in the background
+type operation struct {
+	sync.Mutex
+	op       *node.Operation
+	cancel   context.CancelFunc
+	finished time.Time
+	// changed is closed and replaced on every update so watchers can wait
+	// for the next one
+	changed chan struct{}
+}
+
+func (o *operation) snapshot() (*node.Operation, <-chan struct{}) {
+	o.Lock()
+	defer o.Unlock()
+
+	return proto.Clone(o.op).(*node.Operation), o.changed
+}
+
+func (o *operation) update(f func(op *node.Operation)) {
+	o.Lock()
+	defer o.Unlock()
+
+	f(o.op)
+	if operationDone(o.op) && o.finished.IsZero() {
+		o.finished = time.Now()
+	}
+
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
+
+// reportProgress records the progress copy.Image reports for a layer
+func (o *operation) reportProgress(p types.ProgressProperties) {
+	o.update(func(op *node.Operation) {
+		for _, layer := range op.Layers {
+			if layer.Digest == p.Artifact.Digest.String() {
+				layer.Offset = int64(p.Offset)
+				return
+			}
+		}
+
+		op.Layers = append(op.Layers, &node.LayerProgress{
+			Digest: p.Artifact.Digest.String(),
+			Size:   p.Artifact.Size,
+			Offset: int64(p.Offset),
+		})
+	})
+}
+
+func operationDone(op *node.Operation) bool {
+	switch op.GetState() {
+	case node.OperationState_DONE, node.OperationState_ERROR, node.OperationState_CANCELLED:
+		return true
+	}
+
+	return false
+}
+
+type operations struct {
+	sync.Mutex
+	ops map[string]*operation
+}
+
+func newOperations() *operations {
+	return &operations{ops: make(map[string]*operation)}
+}
+
+// start registers op as a new operation, operations finished longer than
+// operationRetention ago are dropped
+func (t *operations) start(op *node.Operation, cancel context.CancelFunc) *operation {
+	t.Lock()
+	defer t.Unlock()
+
+	for id, o := range t.ops {
+		o.Lock()
+		expired := !o.finished.IsZero() && time.Since(o.finished) > operationRetention
+		o.Unlock()
+		if expired {
+			delete(t.ops, id)
+		}
+	}
+
+	id := uuid.NewV4().String()
+	op.Id = &node.UUID{Value: id}
+	op.State = node.OperationState_PENDING
+	o := &operation{
+		op:      op,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	t.ops[id] = o
+
+	return o
+}
+
+func (t *operations) get(id string) (*operation, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	o, ok := t.ops[id]
+	if !ok {
+		return nil, fmt.Errorf("Operation %s not found", id)
+	}
+
+	return o, nil
+}
+
+// runPull pulls the image of the operation, reporting the progress of
+// every layer copied
+func (s *storage) runPull(ctx context.Context, o *operation, img *node.ImageName) {
+	defer o.cancel()
+
+	o.update(func(op *node.Operation) {
+		op.State = node.OperationState_IN_PROGRESS
+	})
+
+	progress := make(chan types.ProgressProperties)
+	reported := make(chan struct{})
+	go func() {
+		for p := range progress {
+			o.reportProgress(p)
+		}
+		close(reported)
+	}()
+
+	path, err := s.pullImage(ctx, img.GetName(), img.GetCredentials(), progress)
+	close(progress)
+	<-reported
+
+	var sizes *imageSizes
+	if err == nil {
+		_, storedName := splitImagePath(path)
+		sizes, err = s.images.imageSizes(storedName, s.headroom)
+	}
+
+	o.update(func(op *node.Operation) {
+		switch {
+		case err != nil && ctx.Err() == context.Canceled:
+			op.State = node.OperationState_CANCELLED
+		case err != nil:
+			op.State = node.OperationState_ERROR
+			op.Error = err.Error()
+		default:
+			op.State = node.OperationState_DONE
+			op.Path = path
+			op.Size = sizes.compressed
+			op.UnpackedSize = sizes.unpacked
+			op.RequiredSizeMib = sizes.requiredMib
+			// layers found in the store are never reported
+			for _, layer := range op.Layers {
+				layer.Offset = layer.Size
+			}
+		}
+	})
+
+	if err != nil {
+		s.log.Errorf("Pull of %s failed: %s", img.GetName(), err)
+		return
+	}
+
+	s.log.Infof("Pulled %s", img.GetName())
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containers/image/types"
+	"github.com/golang/protobuf/ptypes/empty"
+	digest "github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+func TestOperationProgress(t *testing.T) {
+	ops := newOperations()
+	o := ops.start(&node.Operation{Image: "alpine"}, func() {})
+
+	_, changed := o.snapshot()
+	layer := types.BlobInfo{Digest: digest.FromString("layer"), Size: 100}
+	o.reportProgress(types.ProgressProperties{Artifact: layer, Offset: 10})
+	o.reportProgress(types.ProgressProperties{Artifact: layer, Offset: 60})
+
+	select {
+	case <-changed:
+	default:
+		t.Fatal("watchers were not notified of the progress")
+	}
+
+	op, _ := o.snapshot()
+	if len(op.Layers) != 1 || op.Layers[0].Offset != 60 || op.Layers[0].Size != 100 {
+		t.Errorf("unexpected layer progress %v", op.Layers)
+	}
+
+	o.update(func(op *node.Operation) {
+		op.State = node.OperationState_DONE
+	})
+
+	if got, err := ops.get(op.GetId().GetValue()); err != nil || got != o {
+		t.Errorf("operation not found: %v", err)
+	}
+	if o.finished.IsZero() {
+		t.Error("finished operation has no finish time")
+	}
+}
+
+func TestRPCsDuringPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newImageStore(logrus.New(), ImagesConfig{Dir: filepath.Join(dir, "src")})
+	content := tarGz(t, map[string]int{"etc/hosts": 100})
+	layer := putBlob(t, src, content)
+	index := &imgspecv1.Index{Manifests: []imgspecv1.Descriptor{putImage(t, src, "slow", layer)}}
+	if err = src.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pull blocks reading the layer until it is written to the fifo
+	blob := src.blobPath(layer.Digest)
+	os.Remove(blob)
+	if err = syscall.Mkfifo(blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reading := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		f, err := os.OpenFile(blob, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		close(reading)
+		<-release
+		f.Write(content)
+	}()
+	defer close(release)
+
+	ns := NewNodeService(logrus.New(), Config{
+		Storage: StorageConfig{VolumeTable: filepath.Join(dir, "volumes.json")},
+		Images:  ImagesConfig{Dir: filepath.Join(dir, "images")},
+	})
+
+	op, err := ns.PullImage(context.Background(), &node.ImageName{Name: "oci:" + src.dir + ":slow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reading:
+	case <-time.After(10 * time.Second):
+		t.Fatal("pull did not start copying the layer")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ns.ListImages(context.Background(), &empty.Empty{})
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListImages blocked by the pull")
+	}
+
+	if op, _ = ns.GetOperation(context.Background(), op.GetId()); op.GetState() != node.OperationState_IN_PROGRESS {
+		t.Errorf("pull is %s, expected it to be in progress", op.GetState())
+	}
+}
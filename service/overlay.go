@@ -0,0 +1,308 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+const (
+	overlayReflink    = "reflink"
+	overlayDmSnapshot = "dm-snapshot"
+
+	defaultOverlayDir = "/var/lib/catapult-node/overlays"
+
+	// sectorSize is the unit of device-mapper tables
+	sectorSize = 512
+	// snapshotChunkSectors is the granularity blocks are copied to the COW
+	// device with, 4 KiB
+	snapshotChunkSectors = 8
+	// snapshotExceptionSize is the size of the on-disk record mapping a
+	// chunk of the base to the chunk of the COW device holding it
+	snapshotExceptionSize = 16
+)
+
+// overlayDriver gives VMs a writable root filesystem on top of a read-only
+// base image without copying it
+type overlayDriver interface {
+	// create returns the path of the VM's overlay, an overlay kept from a
+	// previous run of the VM is reused
+	create(base, vmID string) (string, error)
+	// remove releases the overlay, its data is deleted unless keep is set
+	remove(vmID string, keep bool) error
+}
+
+// validateOverlayMode rejects overlay modes there is no driver for
+func validateOverlayMode(mode string) error {
+	switch mode {
+	case "", overlayReflink, overlayDmSnapshot:
+		return nil
+	}
+
+	return fmt.Errorf("Unknown overlay mode %q, expected %s or %s",
+		mode, overlayReflink, overlayDmSnapshot)
+}
+
+// newOverlayDriver returns the driver of the overlay mode, which has been
+// checked by Config.Validate
+func newOverlayDriver(logger *log.Logger, cfg StorageConfig) overlayDriver {
+	dir := cfg.OverlayDir
+	if dir == "" {
+		dir = defaultOverlayDir
+	}
+
+	if cfg.OverlayMode == overlayDmSnapshot {
+		return &dmSnapshotOverlay{log: logger, dir: dir}
+	}
+
+	return &reflinkOverlay{log: logger, dir: dir}
+}
+
+// reflinkOverlay clones the base image, the clone shares all its blocks with
+// the base until they are written. It needs a filesystem supporting
+// reflinks, like XFS or btrfs
+type reflinkOverlay struct {
+	log *log.Logger
+	dir string
+}
+
+func (r *reflinkOverlay) path(vmID string) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%s.img", vmID))
+}
+
+func (r *reflinkOverlay) create(base, vmID string) (string, error) {
+	path := r.path(vmID)
+	if _, err := os.Stat(path); err == nil {
+		err = checkOverlayBase(path, base)
+		if err != nil {
+			return "", err
+		}
+
+		r.log.Infof("Reusing overlay %s", path)
+		return path, nil
+	}
+
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	r.log.Infof("Cloning %s to %s", base, path)
+	err = reflink(base, path)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOverlayBase(path, base)
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (r *reflinkOverlay) remove(vmID string, keep bool) error {
+	if keep {
+		return nil
+	}
+
+	return removeOverlayFile(r.path(vmID))
+}
+
+// overlayBasePath returns the file recording the base image an overlay was
+// made from
+func overlayBasePath(overlay string) string {
+	return overlay + ".base"
+}
+
+func writeOverlayBase(overlay, base string) error {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(overlayBasePath(overlay), []byte(base), 0644)
+}
+
+// checkOverlayBase refuses to reuse a kept overlay on top of another base
+// image than the one it was made from, the VM would boot a mix of both.
+// Overlays kept before the base was recorded are assumed to match
+func checkOverlayBase(overlay, base string) error {
+	recorded, err := ioutil.ReadFile(overlayBasePath(overlay))
+	if os.IsNotExist(err) {
+		return writeOverlayBase(overlay, base)
+	}
+	if err != nil {
+		return err
+	}
+
+	base, err = filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+
+	if string(recorded) != base {
+		return fmt.Errorf("Kept overlay %s was made from %s, not from %s, remove it to use another base image",
+			overlay, recorded, base)
+	}
+
+	return nil
+}
+
+// removeOverlayFile deletes an overlay and the record of its base, files
+// already gone are not an error
+func removeOverlayFile(overlay string) error {
+	for _, path := range []string{overlay, overlayBasePath(overlay)} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dmSnapshotOverlay stacks a device-mapper snapshot on the base image, the
+// blocks written by the VM go to a sparse COW file of its own. Kept COW
+// files are only valid as long as the base image does not change
+type dmSnapshotOverlay struct {
+	log *log.Logger
+	dir string
+}
+
+var loopDeps = regexp.MustCompile(`\((loop\d+)\)`)
+
+func (d *dmSnapshotOverlay) name(vmID string) string {
+	return fmt.Sprintf("catapult-%s", vmID)
+}
+
+func (d *dmSnapshotOverlay) cowPath(vmID string) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%s.cow", vmID))
+}
+
+func (d *dmSnapshotOverlay) create(base, vmID string) (string, error) {
+	info, err := os.Stat(base)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(d.dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	// the COW file is sparse so only the written chunks take space, a kept
+	// one is never shrunk
+	cow := d.cowPath(vmID)
+	if _, err = os.Stat(cow); err == nil {
+		err = checkOverlayBase(cow, base)
+	} else if os.IsNotExist(err) {
+		err = writeOverlayBase(cow, base)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(cow, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	if cowInfo, statErr := f.Stat(); statErr != nil {
+		err = statErr
+	} else if size := cowSize(info.Size()); cowInfo.Size() < size {
+		err = f.Truncate(size)
+	}
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	baseLoop, err := util.ExecuteCommand("losetup", "--find", "--show", "--read-only", base)
+	if err != nil {
+		return "", err
+	}
+
+	cowLoop, err := util.ExecuteCommand("losetup", "--find", "--show", cow)
+	if err != nil {
+		util.ExecuteCommand("losetup", "-d", baseLoop)
+		return "", err
+	}
+
+	// a persistent snapshot keeps its exceptions in the COW device, so a
+	// kept overlay comes back as it was left
+	table := fmt.Sprintf("0 %d snapshot %s %s P %d",
+		info.Size()/sectorSize, baseLoop, cowLoop, snapshotChunkSectors)
+	d.log.Infof("Creating snapshot %s: %s", d.name(vmID), table)
+	_, err = util.ExecuteCommand("dmsetup", "create", d.name(vmID), "--table", table)
+	if err != nil {
+		util.ExecuteCommand("losetup", "-d", cowLoop)
+		util.ExecuteCommand("losetup", "-d", baseLoop)
+		return "", err
+	}
+
+	return filepath.Join("/dev/mapper", d.name(vmID)), nil
+}
+
+// cowSize returns the size of a COW device able to hold every chunk of a
+// base of the given size along with the snapshot's metadata: a header chunk
+// and an exception area chunk in front of every run of data chunks it maps,
+// the next area is allocated as soon as one fills up. A full snapshot is
+// invalidated, so it must never run out of space
+func cowSize(base int64) int64 {
+	chunk := int64(snapshotChunkSectors * sectorSize)
+	chunks := (base + chunk - 1) / chunk
+	perArea := chunk / snapshotExceptionSize
+	areas := chunks/perArea + 1
+
+	return (1 + areas + chunks) * chunk
+}
+
+// remove tears down the snapshot if it is still there, a snapshot already
+// gone, e.g. after a reboot of the host, still gets its COW file removed
+func (d *dmSnapshotOverlay) remove(vmID string, keep bool) error {
+	name := d.name(vmID)
+	_, err := os.Stat(filepath.Join("/dev/mapper", name))
+	if err == nil {
+		err = d.removeSnapshot(name)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if keep {
+		return nil
+	}
+
+	return removeOverlayFile(d.cowPath(vmID))
+}
+
+func (d *dmSnapshotOverlay) removeSnapshot(name string) error {
+	out, err := util.ExecuteCommand("dmsetup", "deps", "-o", "devname", name)
+	if err != nil {
+		return err
+	}
+
+	d.log.Infof("Removing snapshot %s", name)
+	_, err = util.ExecuteCommand("dmsetup", "remove", name)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range loopDeps.FindAllStringSubmatch(out, -1) {
+		_, err = util.ExecuteCommand("losetup", "-d", filepath.Join("/dev", dep[1]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCowSize(t *testing.T) {
+	for base, expected := range map[int64]int64{
+		// header, one exception area and the data chunk
+		1:    3 * 4096,
+		4096: 3 * 4096,
+		// 256 chunks fill an exception area and the next one is allocated
+		256 * 4096: 259 * 4096,
+		257 * 4096: 260 * 4096,
+		1 << 30:    (1 + 1025 + 262144) * 4096,
+	} {
+		if size := cowSize(base); size != expected {
+			t.Errorf("COW of a %d bytes base is %d bytes, expected %d", base, size, expected)
+		}
+	}
+}
+
+func TestValidateOverlayMode(t *testing.T) {
+	for _, mode := range []string{"", overlayReflink, overlayDmSnapshot} {
+		if err := (Config{Storage: StorageConfig{OverlayMode: mode}}).Validate(); err != nil {
+			t.Errorf("overlay mode %q rejected: %s", mode, err)
+		}
+	}
+
+	if err := (Config{Storage: StorageConfig{OverlayMode: "dm_snapshot"}}).Validate(); err == nil {
+		t.Error("unknown overlay mode accepted")
+	}
+}
+
+func TestOverlayBase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "overlays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := &dmSnapshotOverlay{log: logrus.New(), dir: dir}
+	cow := d.cowPath("vm")
+	if err = ioutil.WriteFile(cow, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a COW kept before bases were recorded adopts the first base
+	if err = checkOverlayBase(cow, filepath.Join(dir, "base.img")); err != nil {
+		t.Fatal(err)
+	}
+	if err = checkOverlayBase(cow, filepath.Join(dir, ".", "base.img")); err != nil {
+		t.Errorf("same base refused: %s", err)
+	}
+	if err = checkOverlayBase(cow, filepath.Join(dir, "other.img")); err == nil {
+		t.Error("kept overlay reused on another base")
+	}
+
+	// the snapshot device is long gone
+	if err = d.remove("vm", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{cow, overlayBasePath(cow)} {
+		if _, err = os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+const (
+	defaultProbeTimeout  = 60 * time.Second
+	defaultProbeInterval = 1 * time.Second
+)
+
+type probeFunc func(ctx context.Context) error
+
+// waitReady runs the readiness probe until it succeeds or its timeout
+// expires, in which case the last probe error is returned
+func (f *fc) waitReady(ctx context.Context, probe *node.ReadinessProbe, logger *log.Logger) error {
+	check, err := f.probe(probe)
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultProbeTimeout
+	if probe.GetTimeoutSeconds() > 0 {
+		timeout = time.Duration(probe.GetTimeoutSeconds()) * time.Second
+	}
+
+	interval := defaultProbeInterval
+	if probe.GetIntervalMs() > 0 {
+		interval = time.Duration(probe.GetIntervalMs()) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.Infof("Waiting up to %s for %s probe of VM %s", timeout, probe.GetType(), f.vmID)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		attemptCtx, attemptCancel := context.WithTimeout(ctx, interval)
+		err = check(attemptCtx)
+		attemptCancel()
+		if err == nil {
+			logger.Infof("VM %s is ready", f.vmID)
+			return nil
+		}
+		logger.Debugf("%s probe of VM %s failed: %s", probe.GetType(), f.vmID, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s probe did not succeed within %s: %s",
+				probe.GetType(), timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// validateProbe checks a readiness probe before the VM is started, so a
+// VM isn't booted just to be killed for a probe that can never succeed
+func validateProbe(probe *node.ReadinessProbe) error {
+	var err error
+	switch probe.GetType() {
+	case node.ProbeType_NONE, node.ProbeType_ICMP:
+	case node.ProbeType_TCP, node.ProbeType_HTTP:
+		if probe.GetPort() <= 0 || probe.GetPort() > 65535 {
+			err = fmt.Errorf("%s probe requires a port", probe.GetType())
+		}
+	case node.ProbeType_SERIAL:
+		if probe.GetMarker() == "" {
+			err = fmt.Errorf("Serial probe requires a marker")
+		}
+	default:
+		err = fmt.Errorf("Unsupported probe type %s", probe.GetType())
+	}
+
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	return nil
+}
+
+func (f *fc) probe(probe *node.ReadinessProbe) (probeFunc, error) {
+	err := validateProbe(probe)
+	if err != nil {
+		return nil, err
+	}
+
+	switch probe.GetType() {
+	case node.ProbeType_TCP:
+		return tcpProbe(f.network.ip, probe.GetPort()), nil
+	case node.ProbeType_ICMP:
+		return icmpProbe(f.network.ip), nil
+	case node.ProbeType_HTTP:
+		url := fmt.Sprintf("http://%s%s",
+			net.JoinHostPort(f.network.ip, strconv.Itoa(int(probe.GetPort()))),
+			probe.GetPath())
+		return httpProbe(url), nil
+	case node.ProbeType_SERIAL:
+		return serialProbe(f.consolePath(), probe.GetMarker()), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported probe type %s", probe.GetType())
+}
+
+func tcpProbe(ip string, port int32) probeFunc {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(int(port))))
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+func icmpProbe(ip string) probeFunc {
+	return func(ctx context.Context) error {
+		_, err := util.ExecuteCommandContext(ctx, "ping", "-c", "1", "-W", "1", ip)
+		return err
+	}
+}
+
+func httpProbe(url string) probeFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s returned %s", url, resp.Status)
+		}
+
+		return nil
+	}
+}
+
+func serialProbe(consolePath, marker string) probeFunc {
+	return func(ctx context.Context) error {
+		out, err := ioutil.ReadFile(consolePath)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Contains(out, []byte(marker)) {
+			return fmt.Errorf("marker %q not found in console output", marker)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	uuid "github.com/satori/go.uuid"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+const (
+	defaultRootfsDir = "/var/rootfs"
+
+	guestInitPath  = "sbin/init"
+	guestAgentPath = "sbin/catapult-agent"
+
+	// initReadyMarker is printed on the console once init is done, it can
+	// be used as a serial readiness probe marker
+	initReadyMarker = "catapult: init done"
+)
+
+// minimalInit is installed as /sbin/init in rootfs images that don't have
+// an init of their own, container images usually don't
+var minimalInit = `#!/bin/sh
+# minimal init installed by catapult-node
+mount -t proc proc /proc
+mount -t sysfs sysfs /sys
+mount -t devtmpfs devtmpfs /dev 2>/dev/null
+mkdir -p /dev/pts /run
+mount -t devpts devpts /dev/pts
+mount -t tmpfs tmpfs /run
+[ -f /etc/hostname ] && hostname "$(cat /etc/hostname)"
+ip link set lo up 2>/dev/null
+if [ -x /` + guestAgentPath + ` ]; then
+	/` + guestAgentPath + ` &
+fi
+echo "` + initReadyMarker + `"
+while true; do
+	/bin/sh </dev/console >/dev/console 2>&1
+done
+`
+
+var guestHosts = `127.0.0.1	localhost
+::1	localhost ip6-localhost ip6-loopback
+`
+
+type rootfsRequest struct {
+	image       string
+	credentials *node.RegistryCredentials
+	sizeMib     int64
+	fsType      string
+	init        string
+	files       []*node.FileInjection
+}
+
+// buildRootfs pulls an image and writes it to a raw filesystem image that
+// can be used as a VM's root file system directly
+func (s *storage) buildRootfs(ctx context.Context, req *rootfsRequest) (string, error) {
+	fsType := req.fsType
+	if fsType == "" {
+		fsType = defaultFsType
+	}
+
+	if !supportedFsTypes[fsType] {
+		return "", fmt.Errorf("Unsupported filesystem type %q", fsType)
+	}
+
+	imagePath, err := s.pullImage(ctx, req.image, req.credentials, nil)
+	if err != nil {
+		return "", err
+	}
+
+	sizeMib := req.sizeMib
+	if sizeMib <= 0 {
+		_, ref := splitImagePath(imagePath)
+		sizes, err := s.images.imageSizes(ref, s.headroom)
+		if err != nil {
+			return "", err
+		}
+		sizeMib = sizes.requiredMib
+	}
+
+	err = os.MkdirAll(s.rootfsDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	sanitizedImageName := strings.NewReplacer("/", "-", ":", "-").Replace(req.image)
+	rootfs := filepath.Join(s.rootfsDir,
+		fmt.Sprintf("%s-%s.%s", sanitizedImageName, uuid.NewV4(), fsType))
+
+	err = s.createRootfs(rootfs, imagePath, sizeMib, fsType, req)
+	if err != nil {
+		s.log.Errorf("Failed to build rootfs %s: %s", rootfs, err)
+		os.Remove(rootfs)
+		return "", err
+	}
+
+	s.log.Infof("Built rootfs %s from %s", rootfs, req.image)
+	return rootfs, nil
+}
+
+func (s *storage) createRootfs(rootfs, imagePath string, sizeMib int64, fsType string,
+	req *rootfsRequest) (err error) {
+	s.log.Infof("Creating %d MiB rootfs image %s", sizeMib, rootfs)
+	f, err := os.OpenFile(rootfs, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = f.Truncate(sizeMib * mib)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = mkfs{}.Format(rootfs, fsType)
+	if err != nil {
+		return err
+	}
+
+	mountDir, unmount, err := mountDevice(rootfs, fsType)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		umountErr := unmount()
+		if err == nil {
+			err = umountErr
+		}
+	}()
+
+	err = s.unpackImage(imagePath, mountDir)
+	if err != nil {
+		return err
+	}
+
+	err = s.injectGuestFiles(mountDir, req.init)
+	if err != nil {
+		return err
+	}
+
+	return injectFiles(mountDir, req.files)
+}
+
+// injectGuestFiles installs the init, the agent and the network
+// configuration the guest needs to boot under firecracker
+func (s *storage) injectGuestFiles(root, init string) error {
+	initPath, err := guestPath(root, guestInitPath)
+	if err != nil {
+		return err
+	}
+
+	if init != "" {
+		s.log.Infof("Installing %s as init", init)
+		err = copyFile(init, initPath, 0755)
+		if err != nil {
+			return err
+		}
+	} else if _, err = os.Lstat(initPath); os.IsNotExist(err) {
+		s.log.Info("Installing minimal init")
+		err = writeGuestFile(root, guestInitPath, []byte(minimalInit), 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.agentBinary != "" {
+		s.log.Infof("Installing agent %s", s.agentBinary)
+		agentPath, err := guestPath(root, guestAgentPath)
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(s.agentBinary, agentPath, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the address itself is configured by the kernel's ip= argument
+	err = writeGuestFile(root, "etc/hosts", []byte(guestHosts), 0644)
+	if err != nil {
+		return err
+	}
+
+	return writeGuestFile(root, "etc/resolv.conf", guestResolvConf(), 0644)
+}
+
+// injectFiles writes the requested files into the guest root
+func injectFiles(root string, files []*node.FileInjection) error {
+	for _, f := range files {
+		if splitGuestPath(f.GetPath()) == nil {
+			return fmt.Errorf("Invalid path %q for an injected file", f.GetPath())
+		}
+
+		mode := os.FileMode(f.GetMode()) & os.ModePerm
+		if mode == 0 {
+			mode = 0644
+		}
+
+		err := writeGuestFile(root, f.GetPath(), f.GetContent(), mode)
+		if err != nil {
+			return err
+		}
+
+		path, err := guestPath(root, f.GetPath())
+		if err != nil {
+			return err
+		}
+
+		// the mode given to WriteFile is subject to the umask
+		err = os.Chmod(path, mode)
+		if err != nil {
+			return err
+		}
+
+		err = os.Lchown(path, int(f.GetUid()), int(f.GetGid()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// guestPath resolves path inside the guest root the way the guest would,
+// symlinks are followed relative to root so the result can't escape it.
+// The last element is not resolved since callers replace it
+func guestPath(root, path string) (string, error) {
+	parts := splitGuestPath(path)
+	resolved := "/"
+	for links := 0; len(parts) > 0; {
+		next := filepath.Join(resolved, parts[0])
+		parts = parts[1:]
+		if len(parts) == 0 {
+			resolved = next
+			break
+		}
+
+		fi, err := os.Lstat(filepath.Join(root, next))
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		links++
+		if links > 255 {
+			return "", fmt.Errorf("Too many symlinks resolving %s", path)
+		}
+
+		link, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+
+		if filepath.IsAbs(link) {
+			resolved = "/"
+		}
+
+		// relative links keep their leading ".." elements, Join stops
+		// them at the root
+		var linkParts []string
+		for _, part := range strings.Split(filepath.Clean(link), "/") {
+			if part != "" {
+				linkParts = append(linkParts, part)
+			}
+		}
+		parts = append(linkParts, parts...)
+	}
+
+	return filepath.Join(root, resolved), nil
+}
+
+func splitGuestPath(path string) []string {
+	path = strings.Trim(filepath.Clean("/"+path), "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+// guestResolvConf returns the host's nameservers, loopback ones are of no
+// use to the guest so they are skipped
+func guestResolvConf() []byte {
+	var conf strings.Builder
+	f, err := os.Open("/etc/resolv.conf")
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 || fields[0] != "nameserver" {
+				continue
+			}
+			if ip := net.ParseIP(fields[1]); ip != nil && !ip.IsLoopback() {
+				fmt.Fprintf(&conf, "nameserver %s\n", fields[1])
+			}
+		}
+	}
+
+	if conf.Len() == 0 {
+		return []byte("nameserver 1.1.1.1\n")
+	}
+
+	return []byte(conf.String())
+}
+
+// writeGuestFile writes a file inside the guest root, replacing dangling
+// symlinks images often ship for files like /etc/resolv.conf
+func writeGuestFile(root, guestFile string, content []byte, mode os.FileMode) error {
+	path, err := guestPath(root, guestFile)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	os.Remove(path)
+	return ioutil.WriteFile(path, content, mode)
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+
+	os.Remove(dst)
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+func TestGuestPathStaysInRoot(t *testing.T) {
+	root, err := ioutil.TempDir("", "rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(filepath.Join(root, "usr/sbin"), 0755)
+	os.Symlink("usr/sbin", filepath.Join(root, "sbin"))
+	os.Symlink("/", filepath.Join(root, "escape"))
+	os.Symlink("../../../..", filepath.Join(root, "usr/up"))
+
+	tests := map[string]string{
+		"sbin/init":       "usr/sbin/init",
+		"/etc/hosts":      "etc/hosts",
+		"escape/etc/x":    "etc/x",
+		"usr/up/etc/x":    "etc/x",
+		"../../etc/hosts": "etc/hosts",
+	}
+
+	for path, expected := range tests {
+		got, err := guestPath(root, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != filepath.Join(root, expected) {
+			t.Errorf("\n\tGOT: %s \n\tEXPECTED: %s", got, filepath.Join(root, expected))
+		}
+	}
+}
+
+func TestInjectFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.Symlink("/", filepath.Join(root, "escape"))
+
+	err = injectFiles(root, []*node.FileInjection{
+		{Path: "/etc/hostname", Content: []byte("vm1\n")},
+		{Path: "/escape/root/.ssh/authorized_keys", Mode: 0600, Content: []byte("ssh-ed25519 key")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "etc/hostname"))
+	if err != nil || string(content) != "vm1\n" {
+		t.Errorf("unexpected hostname %q: %v", content, err)
+	}
+
+	info, err := os.Stat(filepath.Join(root, "root/.ssh/authorized_keys"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("authorized_keys has mode %v, expected 0600", info.Mode().Perm())
+	}
+
+	if err = injectFiles(root, []*node.FileInjection{{Path: "/"}}); err == nil {
+		t.Error("injecting a file at the guest root succeeded")
+	}
+}
@@ -4,17 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
-	"strings"
 
+	node "github.com/PUMATeam/catapult-node/pb"
 	"github.com/PUMATeam/catapult-node/util"
 	"github.com/containers/image/copy"
 	"github.com/containers/image/transports/alltransports"
+	"github.com/containers/image/types"
+	"github.com/golang/protobuf/proto"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/containers/image/signature"
 	ociTools "github.com/opencontainers/image-tools/image"
 )
 
@@ -23,44 +22,114 @@ const (
 )
 
 type storage struct {
-	log *log.Logger
+	log            *log.Logger
+	backends       map[string]VolumeBackend
+	defaultBackend string
+	volumes        *volumeTable
+	images         *imageStore
+	rootfsDir      string
+	agentBinary    string
+	headroom       int64
+	overlays       overlayDriver
+}
+
+func newStorage(logger *log.Logger, cfg StorageConfig, images ImagesConfig) *storage {
+	defaultBackend := cfg.Backend
+	if defaultBackend == "" {
+		defaultBackend = backendRBD
+	}
+
+	tablePath := cfg.VolumeTable
+	if tablePath == "" {
+		tablePath = defaultVolumeTable
+	}
+
+	rootfsDir := cfg.RootfsDir
+	if rootfsDir == "" {
+		rootfsDir = defaultRootfsDir
+	}
+
+	volumes, err := loadVolumeTable(tablePath)
+	if err != nil {
+		logger.Errorf("Failed to load volume table %s, starting with an empty one: %s",
+			tablePath, err)
+	}
+
+	return &storage{
+		log:            logger,
+		backends:       newVolumeBackends(logger, cfg),
+		defaultBackend: defaultBackend,
+		volumes:        volumes,
+		images:         newImageStore(logger, images),
+		rootfsDir:      rootfsDir,
+		agentBinary:    cfg.AgentBinary,
+		headroom:       cfg.SizeHeadroomPercent,
+		overlays:       newOverlayDriver(logger, cfg),
+	}
+}
+
+// backend returns the named volume backend, or the node's default one
+func (s *storage) backend(name string) (VolumeBackend, error) {
+	if name == "" {
+		name = s.defaultBackend
+	}
+
+	b, ok := s.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown storage backend %q", name)
+	}
+
+	return b, nil
 }
 
 // TODO create a temporary volume on the storage to handle unpacking
-func (s *storage) pullImage(ctx context.Context, imageName string) (string, error) {
-	sanitizedImageName := strings.Replace(imageName, "/", "-", -1)
-	workingDir := fmt.Sprintf("/var/%s/", sanitizedImageName)
-	s.log.Infof("Creating work dir %s", workingDir)
-	err := os.Mkdir(workingDir, 0755)
+// pullImage copies the image into the image store, progress is reported to
+// the channel if one is given
+func (s *storage) pullImage(ctx context.Context, imageName string, creds *node.RegistryCredentials,
+	progress chan types.ProgressProperties) (string, error) {
+	auth, err := s.images.credentials(creds)
 	if err != nil {
-		s.log.Error("Failed to create directory", err)
+		s.log.Error(err)
 		return "", err
 	}
 
-	policy := &signature.Policy{Default: []signature.PolicyRequirement{
-		signature.NewPRInsecureAcceptAnything(),
-	}}
+	s.images.Lock()
+	err = os.MkdirAll(s.images.dir, 0755)
+	if err == nil {
+		err = s.images.ensureQuota()
+	}
+	var staging string
+	if err == nil {
+		staging, err = s.images.newStaging()
+	}
+	s.images.Unlock()
+	if err != nil {
+		s.log.Error("Failed to prepare the image store: ", err)
+		return "", err
+	}
+	defer os.RemoveAll(staging)
 
-	policyCtx, err := signature.NewPolicyContext(policy)
+	policyCtx, err := s.images.policyContext()
 	if err != nil {
 		s.log.Error("Failed to policy context", err)
 		return "", err
 	}
+	defer policyCtx.Destroy()
 
-	srcImageType, err :=
-		alltransports.ParseImageName(fmt.Sprintf("docker://%s", imageName))
+	srcImageType, storedName, err := imageSource(imageName)
 	if err != nil {
 		s.log.Error("Failed to parse image", err)
 		return "", err
 
 	}
 
-	dstImageType, err :=
-		alltransports.ParseImageName(fmt.Sprintf("oci:/%s:%s", workingDir, tag))
+	dstRef, err :=
+		alltransports.ParseImageName(fmt.Sprintf("oci:%s:%s", staging, storedName))
 	if err != nil {
 		s.log.Error("Failed to parse image", err)
 		return "", err
 	}
+	dstImageType := &stagingReference{ImageReference: dstRef, store: s.images, staging: staging}
 
 	s.log.Infof("Copying image %s...", imageName)
 	_, err = copy.Image(ctx,
@@ -68,28 +137,61 @@ func (s *storage) pullImage(ctx context.Context, imageName string) (string, erro
 		dstImageType,
 		srcImageType,
 		&copy.Options{
-			ReportWriter: s.log.Out,
+			ReportWriter:     s.log.Out,
+			SourceCtx:        s.images.systemContext(auth),
+			DestinationCtx:   s.images.systemContext(nil),
+			Progress:         progress,
+			ProgressInterval: progressInterval,
 		})
 
 	if err != nil {
-		s.log.Error("Failed to copy image")
+		s.log.Error("Failed to copy image: ", err)
+		return "", pullError(err)
+	}
+
+	s.images.Lock()
+	err = s.images.commitStaging(staging, storedName)
+	s.images.Unlock()
+	if err != nil {
+		s.log.Error("Failed to store image: ", err)
 		return "", err
 	}
 
-	return workingDir, nil
+	return s.images.imagePath(storedName), nil
 }
 
-func (s *storage) unpackImage(workingDir, targetDir string) error {
+func (s *storage) unpackImage(imagePath, targetDir string) error {
+	workingDir, ref := splitImagePath(imagePath)
 	s.log.WithFields(
 		log.Fields{
 			"workingDir": workingDir,
+			"ref":        ref,
 			"targetDir":  targetDir}).
 		Info("Unpacking layers...")
+
+	// images of the store are unpacked from a view of their own, pinned so
+	// their blobs outlive a concurrent removal
+	if workingDir == s.images.dir {
+		p, err := s.images.pin(ref)
+		if err != nil {
+			return err
+		}
+		defer s.images.unpin(p)
+
+		view, err := s.images.newView(p)
+		if err != nil {
+			s.log.Error("Failed to prepare image for unpacking")
+			return err
+		}
+		defer os.RemoveAll(view)
+		workingDir = view
+	}
+
 	err := ociTools.UnpackLayout(
 		workingDir,
 		targetDir,
 		"",
-		[]string{fmt.Sprintf("name=%s", tag)})
+		[]string{fmt.Sprintf("name=%s", ref)})
 
 	if err != nil {
 		s.log.Error("Failed to unpack layers")
@@ -99,67 +201,277 @@ func (s *storage) unpackImage(workingDir, targetDir string) error {
 	return nil
 }
 
-func (s *storage) mapVolume(volumeID, pool, imagePath string) (string, error) {
-	command := []string{"map", fmt.Sprintf("%s/volume-%s", pool, volumeID)}
-	s.log.Infof("Executing command rbd-nbd with parameters %v", command)
-	out, err := util.ExecuteCommand("rbd-nbd", command...)
+func (s *storage) mapVolume(vol *node.Volume) (string, error) {
+	err := checkName("volume ID", vol.GetVolumeID())
+	if err == nil {
+		err = s.volumes.reserve(vol.GetVolumeID())
+	}
 	if err != nil {
-		s.log.Errorf("rbd-nbd failed %s", err)
+		s.log.Error(err)
 		return "", err
 	}
+	defer s.volumes.unreserve(vol.GetVolumeID())
 
-	s.log.Infof("Creating ext4 filesystem on %s", out)
-	cmd := exec.Command("mkfs.ext4", "-F", out)
-	err = cmd.Run()
+	backend, err := s.backend(vol.GetBackend())
 	if err != nil {
-		s.log.Error("Failed to create filesystem: ", err)
+		s.log.Error(err)
 		return "", err
 	}
 
-	mountDir := path.Join("/tmp", volumeID)
-	s.log.Infof("Mounting %s on %s", out, mountDir)
-	err = os.Mkdir(mountDir, 0755)
-	cmd = exec.Command("mount", out, mountDir)
-	err = cmd.Run()
+	vol, err = s.sizeVolume(vol)
 	if err != nil {
-		s.log.Errorf("Failed to mount %s", mountDir)
+		s.log.Error(err)
 		return "", err
 	}
 
-	err = os.Remove(path.Join(mountDir, "lost+found"))
+	out, created, err := backend.Attach(vol)
 	if err != nil {
-		s.log.Error("Failed to remove lost+found: ", err)
+		s.log.Errorf("Failed to attach volume %s: %s", vol.GetVolumeID(), err)
 		return "", err
 	}
 
-	err = s.unpackImage(imagePath, mountDir)
+	// a volume that could not be set up is not left connected, nor with a
+	// filesystem a retry would refuse to format
+	connected, formatted := false, false
+	defer func() {
+		if !connected {
+			s.rollbackVolume(backend, vol, out, created, formatted)
+		}
+	}()
+
+	backendName := vol.GetBackend()
+	if backendName == "" {
+		backendName = s.defaultBackend
+	}
+	err = s.volumes.add(&volumeMapping{
+		VolumeID: vol.GetVolumeID(),
+		PoolName: vol.GetPoolName(),
+		Backend:  backendName,
+		Device:   out,
+		Erase:    vol.GetErase(),
+	})
 	if err != nil {
-		s.log.Error("Failed to unpack image: ", err)
+		s.log.Errorf("Failed to record volume %s: %s", vol.GetVolumeID(), err)
 		return "", err
 	}
 
-	s.log.Infof("Unmounting %s", mountDir)
-	cmd = exec.Command("umount", out)
-	err = cmd.Run()
-	if err != nil {
-		s.log.Errorf("Failed to umount %s", mountDir)
-		return "", err
+	if vol.GetMode() != node.ConnectMode_ATTACH_ONLY {
+		err = s.formatVolume(backend, vol, out)
+		if err != nil {
+			return "", err
+		}
+		formatted = true
 	}
-	// TODO: check the drive actually exists
 
+	if vol.GetMode() == node.ConnectMode_FORMAT_AND_POPULATE {
+		err = s.populateVolume(vol, out)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	connected = true
 	return out, nil
 }
 
-func (s *storage) dirSize(dir string) (int64, error) {
-	var size int64
-	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+// rollbackVolume detaches a volume mapVolume failed to set up. A volume
+// created by Attach is deleted again, the filesystem created on an existing
+// one is wiped and its data erased as the volume asks for
+func (s *storage) rollbackVolume(backend VolumeBackend, vol *node.Volume, device string,
+	created, formatted bool) {
+	if formatted && !created {
+		if vol.GetErase() != node.EraseMode_KEEP_DATA {
+			err := s.eraseDevice(context.Background(), device, vol.GetErase(), s.logErase(vol.GetVolumeID()))
+			if err != nil {
+				s.log.Errorf("Failed to erase volume %s: %s", vol.GetVolumeID(), err)
+			}
+		}
+
+		s.log.Infof("Wiping the filesystem of volume %s", vol.GetVolumeID())
+		_, err := util.ExecuteCommand("wipefs", "-a", device)
+		if err != nil {
+			s.log.Errorf("Failed to wipe volume %s: %s", vol.GetVolumeID(), err)
+		}
+	}
+
+	s.log.Infof("Detaching volume %s after failing to connect it", vol.GetVolumeID())
+	err := backend.Detach(vol, device)
+	if err != nil {
+		s.log.Errorf("Failed to detach volume %s from %s: %s", vol.GetVolumeID(), device, err)
+	} else if created {
+		err = backend.Delete(vol)
+		if err != nil {
+			s.log.Errorf("Failed to delete volume %s: %s", vol.GetVolumeID(), err)
+		}
+	}
+
+	if s.volumes.get(vol.GetVolumeID()) != nil {
+		err = s.volumes.remove(vol.GetVolumeID())
+		if err != nil {
+			s.log.Errorf("Failed to forget volume %s: %s", vol.GetVolumeID(), err)
+		}
+	}
+}
+
+// sizeVolume makes sure a volume populated from an image is large enough
+// for it, volumes without a size get the size the image needs
+func (s *storage) sizeVolume(vol *node.Volume) (*node.Volume, error) {
+	if vol.GetMode() != node.ConnectMode_FORMAT_AND_POPULATE || vol.GetImagePath() == "" {
+		return vol, nil
+	}
+
+	dir, ref := splitImagePath(vol.GetImagePath())
+	if dir != s.images.dir {
+		s.log.Warnf("Image %s is not in the image store, not sizing volume %s",
+			vol.GetImagePath(), vol.GetVolumeID())
+		return vol, nil
+	}
+
+	sizes, err := s.images.imageSizes(ref, s.headroom)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to size volume %s: %s", vol.GetVolumeID(), err)
+	}
+
+	if vol.GetSizeMib() <= 0 {
+		s.log.Infof("Sizing volume %s to %d MiB for image %s",
+			vol.GetVolumeID(), sizes.requiredMib, ref)
+		vol = proto.Clone(vol).(*node.Volume)
+		vol.SizeMib = sizes.requiredMib
+		return vol, nil
+	}
+
+	if vol.GetSizeMib() < sizes.requiredMib {
+		return nil, fmt.Errorf("Volume %s of %d MiB is too small for image %s, it needs %d MiB",
+			vol.GetVolumeID(), vol.GetSizeMib(), ref, sizes.requiredMib)
+	}
+
+	return vol, nil
+}
+
+// formatVolume creates the requested filesystem on the device, refusing to
+// overwrite an existing one unless asked to
+func (s *storage) formatVolume(backend VolumeBackend, vol *node.Volume, device string) error {
+	fsType := vol.GetFsType()
+	if fsType == "" {
+		fsType = defaultFsType
+	}
+
+	if !supportedFsTypes[fsType] {
+		err := fmt.Errorf("Unsupported filesystem type %q", fsType)
+		s.log.Error(err)
+		return err
+	}
+
+	existing, err := filesystemType(device)
+	if err != nil {
+		s.log.Errorf("Failed to probe %s for a filesystem: %s", device, err)
+		return err
+	}
+
+	if existing != "" && !vol.GetForce() {
+		err = fmt.Errorf("Volume %s already has a %s filesystem, not formatting it",
+			vol.GetVolumeID(), existing)
+		s.log.Error(err)
+		return err
+	}
+
+	if vol.GetSizeMib() > 0 {
+		stat, err := backend.Stat(vol)
 		if err != nil {
+			s.log.Errorf("Failed to stat volume %s: %s", vol.GetVolumeID(), err)
 			return err
 		}
-		if !info.IsDir() {
-			size += info.Size()
+
+		if stat.SizeBytes < vol.GetSizeMib()*mib {
+			err = backend.Resize(vol, vol.GetSizeMib())
+			if err != nil {
+				s.log.Errorf("Failed to resize volume %s: %s", vol.GetVolumeID(), err)
+				return err
+			}
 		}
+	}
+
+	s.log.Infof("Creating %s filesystem on %s", fsType, device)
+	err = backend.Format(device, fsType)
+	if err != nil {
+		s.log.Error("Failed to create filesystem: ", err)
 		return err
-	})
-	return size, err
+	}
+
+	return nil
+}
+
+// populateVolume unpacks the volume's image onto the filesystem on device
+func (s *storage) populateVolume(vol *node.Volume, device string) (err error) {
+	mountDir, unmount, err := mountDevice(device, vol.GetFsType())
+	if err != nil {
+		s.log.Error(err)
+		return err
+	}
+	s.log.Infof("Mounted %s on %s", device, mountDir)
+
+	defer func() {
+		s.log.Infof("Unmounting %s", mountDir)
+		umountErr := unmount()
+		if umountErr != nil {
+			s.log.Error(umountErr)
+			if err == nil {
+				err = umountErr
+			}
+		}
+	}()
+
+	err = os.Remove(filepath.Join(mountDir, "lost+found"))
+	if err != nil && !os.IsNotExist(err) {
+		s.log.Error("Failed to remove lost+found: ", err)
+		return err
+	}
+
+	err = s.unpackImage(vol.GetImagePath(), mountDir)
+	if err != nil {
+		s.log.Error("Failed to unpack image: ", err)
+		return err
+	}
+
+	err = injectFiles(mountDir, vol.GetFiles())
+	if err != nil {
+		s.log.Error("Failed to inject files: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// unmapVolume detaches a connected volume from the node and forgets it. The
+// volume is erased first with erase, or as asked when it was connected if
+// erase keeps the data
+func (s *storage) unmapVolume(ctx context.Context, volumeID string, erase node.EraseMode,
+	progress func(erased, total int64)) error {
+	m := s.volumes.get(volumeID)
+	if m == nil {
+		return fmt.Errorf("Volume %s is not connected", volumeID)
+	}
+
+	backend, err := s.backend(m.Backend)
+	if err != nil {
+		return err
+	}
+
+	if erase == node.EraseMode_KEEP_DATA {
+		erase = m.Erase
+	}
+	if erase != node.EraseMode_KEEP_DATA {
+		err = s.eraseDevice(ctx, m.Device, erase, progress)
+		if err != nil {
+			return fmt.Errorf("Failed to erase volume %s: %s", volumeID, err)
+		}
+	}
+
+	s.log.Infof("Detaching volume %s from %s", volumeID, m.Device)
+	err = backend.Detach(m.volume(), m.Device)
+	if err != nil {
+		return fmt.Errorf("Failed to detach volume %s: %s", volumeID, err)
+	}
+
+	return s.volumes.remove(volumeID)
 }
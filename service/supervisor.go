@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+const (
+	defaultRestartBackoff    = 1 * time.Second
+	defaultMaxRestartBackoff = 1 * time.Minute
+
+	// a VM that ran for longer than this is considered healthy again and
+	// its restart backoff starts over
+	restartResetAfter = 10 * time.Minute
+)
+
+// supervise waits for the firecracker process of a VM to exit, records how
+// it exited and restarts it according to the VM's restart policy. Restarted
+// VMs keep their tap device, addresses and drives
+func (ns *NodeService) supervise(v *vm) {
+	attempts := 0
+	for {
+		started := time.Now()
+		v.Lock()
+		m := v.machine
+		v.Unlock()
+
+		err := m.Wait(context.Background())
+		if v.isStopped() {
+			return
+		}
+
+		if time.Since(started) > restartResetAfter {
+			attempts = 0
+		}
+
+		if !ns.recordExit(v, err, attempts) {
+			return
+		}
+
+		for {
+			select {
+			case <-v.stopped:
+				return
+			case <-time.After(restartBackoff(v.cfg.GetRestart(), attempts)):
+			}
+
+			attempts++
+			err = ns.restartVM(v)
+			if err == nil {
+				break
+			}
+
+			if !ns.recordExit(v, fmt.Errorf("restart failed: %s", err), attempts) {
+				return
+			}
+		}
+	}
+}
+
+// recordExit stores the exit status of the VM and reports whether it
+// should be restarted
+func (ns *NodeService) recordExit(v *vm, exitErr error, attempts int) bool {
+	v.Lock()
+	defer v.Unlock()
+
+	vmID := v.fc.vmID
+	v.exitStatus = "exited with status 0"
+	if exitErr != nil {
+		v.exitStatus = exitErr.Error()
+	}
+	ns.log.Warnf("VM %s %s", vmID, v.exitStatus)
+
+	if !shouldRestart(v.cfg.GetRestart(), exitErr, attempts) {
+		v.state = node.VmState_STOPPED
+		if exitErr != nil {
+			v.state = node.VmState_CRASHED
+		}
+		ns.log.Infof("Not restarting VM %s after %d attempts", vmID, attempts)
+		return false
+	}
+
+	v.state = node.VmState_STARTING
+	return true
+}
+
+// restartVM starts a new firecracker process for the VM. The VM isn't
+// locked while the process starts, the new machine is swapped in once it
+// runs unless the VM was stopped in the meantime
+func (ns *NodeService) restartVM(v *vm) error {
+	v.Lock()
+	if v.isStopped() {
+		v.Unlock()
+		return nil
+	}
+
+	ns.log.Infof("Restarting VM %s", v.fc.vmID)
+	v.fc.closeConsole()
+	// CNI networks are deleted and added again for the new VMM, which may
+	// get another address, the copy keeps the running VM's network as is
+	// until the new machine is swapped in
+	network := *v.fc.network
+	restarted := &fc{
+		vmID:     v.fc.vmID,
+		network:  &network,
+		vsockCID: v.fc.vsockCID,
+	}
+	cfg := proto.Clone(v.cfg).(*node.VmConfig)
+	v.Unlock()
+
+	err := restarted.rotateConsole()
+	if err != nil {
+		ns.log.Warnf("Failed to keep the console log of VM %s: %s", restarted.vmID, err)
+	}
+
+	m, err := restarted.runVMM(context.Background(), cfg, ns.log)
+	if err != nil {
+		return err
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	if v.isStopped() {
+		ns.log.Infof("VM %s was stopped while restarting, stopping it again", v.fc.vmID)
+		m.StopVMM()
+		restarted.closeConsole()
+		return nil
+	}
+
+	if old := v.fc.network; old.ip != network.ip || old.tapDevice != network.tapDevice {
+		ns.log.Warnf("VM %s restarted with address %s on %s, it had %s on %s",
+			v.fc.vmID, network.ip, network.tapDevice, old.ip, old.tapDevice)
+	}
+
+	v.machine = m
+	v.fc = restarted
+	v.cfg.Address = restarted.network.ip
+	v.state = node.VmState_RUNNING
+	v.restarts++
+
+	go restarted.readPipe(ns.log, "log")
+	go restarted.readPipe(ns.log, "metrics")
+
+	return nil
+}
+
+func shouldRestart(cfg *node.RestartConfig, exitErr error, attempts int) bool {
+	switch cfg.GetPolicy() {
+	case node.RestartPolicy_ALWAYS:
+		return true
+	case node.RestartPolicy_ON_FAILURE:
+		return exitErr != nil &&
+			(cfg.GetMaxRetries() == 0 || attempts < int(cfg.GetMaxRetries()))
+	}
+
+	return false
+}
+
+// restartBackoff doubles the configured backoff for every consecutive
+// restart attempt, up to the configured maximum
+func restartBackoff(cfg *node.RestartConfig, attempts int) time.Duration {
+	backoff := defaultRestartBackoff
+	if cfg.GetBackoffMs() > 0 {
+		backoff = time.Duration(cfg.GetBackoffMs()) * time.Millisecond
+	}
+
+	maxBackoff := defaultMaxRestartBackoff
+	if cfg.GetMaxBackoffMs() > 0 {
+		maxBackoff = time.Duration(cfg.GetMaxBackoffMs()) * time.Millisecond
+	}
+
+	for i := 0; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
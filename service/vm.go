@@ -0,0 +1,56 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/firecracker-microvm/firecracker-go-sdk"
+	"github.com/golang/protobuf/proto"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+// vm tracks a machine started by the node together with the
+// configuration it was started with and its current state
+type vm struct {
+	sync.Mutex
+	machine *firecracker.Machine
+	fc      *fc
+	cfg     *node.VmConfig
+	state   node.VmState
+
+	// exitStatus describes how the firecracker process last exited
+	exitStatus string
+	// restarts counts the restarts done by the supervisor
+	restarts int32
+	// stopped is closed once the VM is stopped through the API, so the
+	// supervisor does not try to bring it back
+	stopped chan struct{}
+	// removed is set by the removeVM call releasing the VM's resources,
+	// it is guarded by the node's lock
+	removed bool
+}
+
+func (v *vm) info() *node.VmInfo {
+	v.Lock()
+	defer v.Unlock()
+
+	// the config is copied since it may be updated while the reply is
+	// being marshalled
+	cfg := proto.Clone(v.cfg).(*node.VmConfig)
+	return &node.VmInfo{
+		VmID:       cfg.GetVmID(),
+		State:      v.state,
+		Config:     cfg,
+		ExitStatus: v.exitStatus,
+		Restarts:   v.restarts,
+	}
+}
+
+func (v *vm) isStopped() bool {
+	select {
+	case <-v.stopped:
+		return true
+	default:
+		return false
+	}
+}
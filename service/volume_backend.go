@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+const (
+	backendRBD  = "rbd"
+	backendLVM  = "lvm"
+	backendFile = "file"
+	backendLoop = "loop"
+
+	defaultVolumeDir = "/var/volumes"
+
+	mib = 1024 * 1024
+
+	defaultFsType = "ext4"
+)
+
+var supportedFsTypes = map[string]bool{
+	"ext4": true,
+	"xfs":  true,
+}
+
+// VolumeBackend provides the block devices volumes are connected as
+type VolumeBackend interface {
+	// Attach makes the volume available on the node, creating it if the
+	// backend supports it, and returns the path of its block device and
+	// whether it was created
+	Attach(vol *node.Volume) (string, bool, error)
+	// Detach releases the device returned by Attach
+	Detach(vol *node.Volume, device string) error
+	// Delete removes a detached volume along with its data
+	Delete(vol *node.Volume) error
+	// Format creates a filesystem of the given type on the device
+	Format(device, fsType string) error
+	// Resize grows the volume to sizeMib
+	Resize(vol *node.Volume, sizeMib int64) error
+	// Stat reports the size of the volume
+	Stat(vol *node.Volume) (*VolumeStat, error)
+	// Snapshot takes a point in time snapshot of the volume
+	Snapshot(vol *node.Volume, snapshot string) error
+	// DeleteSnapshot removes a snapshot taken with Snapshot
+	DeleteSnapshot(vol *node.Volume, snapshot string) error
+	// Clone creates the target volume from a snapshot of vol, sharing
+	// the unchanged data with it where the backend allows
+	Clone(vol *node.Volume, snapshot string, target *node.Volume) error
+}
+
+// VolumeStat describes a volume as seen by its backend
+type VolumeStat struct {
+	SizeBytes      int64
+	AllocatedBytes int64
+}
+
+func newVolumeBackends(logger *log.Logger, cfg StorageConfig) map[string]VolumeBackend {
+	dir := cfg.VolumeDir
+	if dir == "" {
+		dir = defaultVolumeDir
+	}
+
+	files := &fileBackend{log: logger, dir: dir}
+	return map[string]VolumeBackend{
+		backendRBD: &rbdBackend{log: logger},
+		backendLVM: &lvmBackend{
+			log:         logger,
+			volumeGroup: cfg.VolumeGroup,
+			thinPool:    cfg.ThinPool,
+		},
+		backendFile: files,
+		backendLoop: &loopBackend{fileBackend: files},
+	}
+}
+
+func volumeName(vol *node.Volume) string {
+	return fmt.Sprintf("volume-%s", vol.GetVolumeID())
+}
+
+// mkfs implements VolumeBackend.Format for backends whose devices can be
+// formatted directly
+type mkfs struct{}
+
+func (mkfs) Format(device, fsType string) error {
+	if fsType == "" {
+		fsType = defaultFsType
+	}
+
+	force := "-F"
+	if fsType == "xfs" {
+		force = "-f"
+	}
+
+	_, err := util.ExecuteCommand(fmt.Sprintf("mkfs.%s", fsType), force, device)
+	return err
+}
+
+// filesystemType returns the type of the filesystem on device, or an empty
+// string if there is none
+func filesystemType(device string) (string, error) {
+	out, err := exec.Command("blkid", "-p", "-o", "value", "-s", "TYPE", device).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+		// blkid exits with 2 when nothing was detected
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("blkid failed on %s: %s", device, err)
+	}
+
+	return string(bytes.TrimSpace(out)), nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+// eraseChunkSize is how much is zeroed at a time, cancellation is checked
+// between chunks
+const eraseChunkSize = 4 * mib
+
+// eraseDevice removes the data on the device of a connected volume, progress
+// is called with the bytes erased so far
+func (s *storage) eraseDevice(ctx context.Context, device string, mode node.EraseMode,
+	progress func(erased, total int64)) error {
+	total, err := deviceSize(device)
+	if err != nil {
+		return err
+	}
+
+	progress(0, total)
+	s.log.Infof("Erasing %s with %s", device, mode)
+	switch mode {
+	case node.EraseMode_DISCARD:
+		err = discard(device, total)
+	case node.EraseMode_ZERO_FILL:
+		err = zeroFill(ctx, device, total, progress)
+	}
+	if err != nil {
+		return err
+	}
+
+	progress(total, total)
+	return nil
+}
+
+// discard releases all blocks of a device, file volumes get holes punched
+// instead
+func discard(device string, size int64) error {
+	info, err := os.Stat(device)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		_, err = util.ExecuteCommand("blkdiscard", device)
+		return err
+	}
+
+	f, err := os.OpenFile(device, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return unix.Fallocate(int(f.Fd()), unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, 0, size)
+}
+
+// zeroFill overwrites the device with zeros, reporting progress at most
+// every progressInterval
+func zeroFill(ctx context.Context, device string, size int64, progress func(erased, total int64)) error {
+	f, err := os.OpenFile(device, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zeros := make([]byte, eraseChunkSize)
+	reported := time.Now()
+	for written := int64(0); written < size; {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		chunk := zeros
+		if size-written < int64(len(chunk)) {
+			chunk = chunk[:size-written]
+		}
+
+		n, err := f.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return err
+		}
+
+		if time.Since(reported) >= progressInterval {
+			progress(written, size)
+			reported = time.Now()
+		}
+	}
+
+	return f.Sync()
+}
+
+// logErase returns an erase progress callback logging the progress
+func (s *storage) logErase(volumeID string) func(erased, total int64) {
+	return func(erased, total int64) {
+		s.log.Infof("Erased %d of %d bytes of volume %s", erased, total, volumeID)
+	}
+}
+
+// runRelease erases a volume reserved for being disconnected and
+// disconnects it, reporting the progress to the operation
+func (s *storage) runRelease(ctx context.Context, o *operation, vol *node.Volume) {
+	defer o.cancel()
+	defer s.volumes.unreserve(vol.GetVolumeID())
+
+	o.update(func(op *node.Operation) {
+		op.State = node.OperationState_IN_PROGRESS
+	})
+
+	err := s.unmapVolume(ctx, vol.GetVolumeID(), vol.GetErase(), func(erased, total int64) {
+		o.update(func(op *node.Operation) {
+			op.BytesErased = erased
+			op.BytesTotal = total
+		})
+	})
+
+	o.update(func(op *node.Operation) {
+		switch {
+		case err != nil && ctx.Err() == context.Canceled:
+			op.State = node.OperationState_CANCELLED
+		case err != nil:
+			op.State = node.OperationState_ERROR
+			op.Error = err.Error()
+		default:
+			op.State = node.OperationState_DONE
+		}
+	})
+
+	if err != nil {
+		s.log.Errorf("Release of volume %s failed: %s", vol.GetVolumeID(), err)
+		return
+	}
+
+	s.log.Infof("Released volume %s", vol.GetVolumeID())
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+func TestEraseDevice(t *testing.T) {
+	s := &storage{log: logrus.New()}
+	data := bytes.Repeat([]byte("tenant data "), mib)
+
+	for _, mode := range []node.EraseMode{node.EraseMode_DISCARD, node.EraseMode_ZERO_FILL} {
+		f, err := ioutil.TempFile("", "volume")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		f.Write(data)
+		f.Close()
+
+		var erased, total int64
+		err = s.eraseDevice(context.Background(), f.Name(), mode, func(e, t int64) {
+			erased, total = e, t
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", mode, err)
+		}
+
+		if erased != int64(len(data)) || total != int64(len(data)) {
+			t.Errorf("%s reported %d of %d bytes erased", mode, erased, total)
+		}
+
+		content, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(content) != len(data) || !bytes.Equal(content, make([]byte, len(data))) {
+			t.Errorf("%s left data on the volume", mode)
+		}
+	}
+}
+
+func TestUnmapVolumeErasesAsConnected(t *testing.T) {
+	if _, err := exec.LookPath("mkfs.ext4"); err != nil {
+		t.Skip("needs mkfs.ext4")
+	}
+
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := StorageConfig{
+		Backend:     backendFile,
+		VolumeDir:   dir,
+		VolumeTable: filepath.Join(dir, "volumes.json"),
+	}
+	s := newStorage(logrus.New(), cfg, ImagesConfig{Dir: filepath.Join(dir, "images")})
+
+	vol := &node.Volume{
+		VolumeID: "erased",
+		SizeMib:  8,
+		Mode:     node.ConnectMode_FORMAT_EMPTY,
+		Erase:    node.EraseMode_ZERO_FILL,
+	}
+	device, err := s.mapVolume(vol)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the erase mode outlives restarts of the node
+	s = newStorage(logrus.New(), cfg, ImagesConfig{Dir: filepath.Join(dir, "images")})
+	if m := s.volumes.get(vol.GetVolumeID()); m == nil || m.Erase != node.EraseMode_ZERO_FILL {
+		t.Fatalf("erase mode was not recorded: %+v", m)
+	}
+
+	err = s.unmapVolume(context.Background(), vol.GetVolumeID(), node.EraseMode_KEEP_DATA, func(e, t int64) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, make([]byte, len(content))) {
+		t.Error("volume was not erased when disconnected")
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+	"github.com/PUMATeam/catapult-node/util"
+)
+
+const (
+	// guestRootDevice is how the guest sees the root drive
+	guestRootDevice = "/dev/vda"
+
+	// deviceResizeTimeout is how long rbd-nbd gets to notice the image
+	// was resized
+	deviceResizeTimeout = 10 * time.Second
+)
+
+// resizeVolume grows a volume to sizeMib. It returns the device of the
+// volume if it is connected, once the device reports the new size
+func (s *storage) resizeVolume(vol *node.Volume, sizeMib int64) (string, error) {
+	err := checkName("volume ID", vol.GetVolumeID())
+	if err != nil {
+		return "", err
+	}
+
+	m := s.volumes.get(vol.GetVolumeID())
+	if m != nil {
+		vol = m.volume()
+	}
+
+	backend, err := s.backend(vol.GetBackend())
+	if err != nil {
+		return "", err
+	}
+
+	stat, err := backend.Stat(vol)
+	if err != nil {
+		return "", fmt.Errorf("Failed to stat volume %s: %s", vol.GetVolumeID(), err)
+	}
+
+	if sizeMib*mib < stat.SizeBytes {
+		return "", fmt.Errorf("Volume %s is %d MiB, it can't be shrunk to %d MiB",
+			vol.GetVolumeID(), stat.SizeBytes/mib, sizeMib)
+	}
+
+	err = backend.Resize(vol, sizeMib)
+	if err != nil {
+		return "", fmt.Errorf("Failed to resize volume %s: %s", vol.GetVolumeID(), err)
+	}
+
+	if m == nil {
+		return "", nil
+	}
+
+	return m.Device, waitDeviceSize(m.Device, sizeMib*mib)
+}
+
+// waitDeviceSize waits for the device to report its new size, rbd-nbd
+// updates the nbd device when it is notified of the resize
+func waitDeviceSize(device string, size int64) error {
+	deadline := time.Now().Add(deviceResizeTimeout)
+	for {
+		current, err := deviceSize(device)
+		if err != nil {
+			return err
+		}
+
+		if current >= size {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s is still %d bytes after resizing it to %d", device, current, size)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// deviceSize returns the size of a block device or of a file backed volume
+func deviceSize(device string) (int64, error) {
+	info, err := os.Stat(device)
+	if err != nil {
+		return 0, err
+	}
+
+	if info.Mode().IsRegular() {
+		return info.Size(), nil
+	}
+
+	out, err := util.ExecuteCommand("blockdev", "--getsize64", device)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// growFilesystem grows the filesystem on a device no VM is using, only ext4
+// can be grown without mounting it
+func (s *storage) growFilesystem(device string) error {
+	fsType, err := filesystemType(device)
+	if err != nil {
+		return err
+	}
+
+	switch fsType {
+	case "":
+		return nil
+	case "ext4":
+		s.log.Infof("Growing filesystem on %s", device)
+		// resize2fs refuses to work on filesystems not checked since
+		// they were last mounted
+		err = s.checkExt4(device)
+		if err != nil {
+			return err
+		}
+
+		_, err = util.ExecuteCommand("resize2fs", device)
+		return err
+	}
+
+	s.log.Warnf("Not growing the %s filesystem on %s, it is grown once mounted", fsType, device)
+	return nil
+}
+
+// checkExt4 checks and repairs the ext4 filesystem on device. e2fsck exits
+// with 1 when it fixed errors and with 2 when the system should be rebooted,
+// which only matters for mounted filesystems, so only 4 and above are
+// failures, as is an e2fsck killed by a signal
+func (s *storage) checkExt4(device string) error {
+	return s.runFsck(exec.Command("e2fsck", "-f", "-p", device), device)
+}
+
+// runFsck runs an e2fsck command and interprets its exit code
+func (s *storage) runFsck(cmd *exec.Cmd, device string) error {
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// ExitCode is -1 if e2fsck was killed
+		if code := exitErr.ExitCode(); code >= 0 && code < 4 {
+			s.log.Warnf("e2fsck fixed errors on %s: %s", device, bytes.TrimSpace(out))
+			return nil
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("e2fsck failed on %s with %q: %s", device, bytes.TrimSpace(out), err)
+	}
+
+	return nil
+}
+
+// guestGrowCommand returns the command growing the root filesystem of a
+// guest
+func guestGrowCommand(fsType string) (string, []string, error) {
+	switch fsType {
+	case "ext4":
+		return "resize2fs", []string{guestRootDevice}, nil
+	case "xfs":
+		return "xfs_growfs", []string{"/"}, nil
+	}
+
+	return "", nil, fmt.Errorf("Can't grow a %q filesystem", fsType)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGrowFilesystemAfterFsckFixes(t *testing.T) {
+	for _, tool := range []string{"mkfs.ext4", "debugfs", "e2fsck", "resize2fs", "dumpe2fs"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "resize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	image := filepath.Join(dir, "volume.img")
+	for _, cmd := range [][]string{
+		{"truncate", "-s", "32M", image},
+		{"mkfs.ext4", "-q", "-F", image},
+		// e2fsck -p silently fixes a wrong free blocks count and exits
+		// with 1
+		{"debugfs", "-w", "-R", "ssv free_blocks_count 10", image},
+		{"truncate", "-s", "64M", image},
+	} {
+		if out, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s: %s", cmd, err, out)
+		}
+	}
+
+	s := &storage{log: logrus.New()}
+	if err = s.growFilesystem(image); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("dumpe2fs", "-h", image).CombinedOutput()
+	if err != nil {
+		t.Fatalf("dumpe2fs failed: %s: %s", err, out)
+	}
+	if !bytes.Contains(out, []byte("Block count:              65536")) {
+		t.Errorf("filesystem was not grown:\n%s", out)
+	}
+}
+
+func TestRunFsckExitCodes(t *testing.T) {
+	s := &storage{log: logrus.New()}
+	for script, ok := range map[string]bool{
+		"exit 0": true,
+		"exit 1": true,
+		"exit 2": true,
+		"exit 4": false,
+		"exit 8": false,
+		// a killed e2fsck has not checked anything
+		"kill -9 $$": false,
+	} {
+		err := s.runFsck(exec.Command("sh", "-c", script), "/dev/null")
+		if ok != (err == nil) {
+			t.Errorf("e2fsck doing %q returned %v", script, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+// snapshotBackend returns the backend of the snapshot's volume after checking
+// the snapshot name and volume ID
+func (s *storage) snapshotBackend(snap *node.VolumeSnapshot) (VolumeBackend, error) {
+	err := checkName("snapshot name", snap.GetName())
+	if err == nil {
+		err = checkName("volume ID", snap.GetVolume().GetVolumeID())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.backend(snap.GetVolume().GetBackend())
+}
+
+func (s *storage) snapshotVolume(snap *node.VolumeSnapshot) error {
+	backend, err := s.snapshotBackend(snap)
+	if err != nil {
+		return err
+	}
+
+	err = backend.Snapshot(snap.GetVolume(), snap.GetName())
+	if err != nil {
+		return fmt.Errorf("Failed to snapshot volume %s: %s", snap.GetVolume().GetVolumeID(), err)
+	}
+
+	return nil
+}
+
+func (s *storage) deleteSnapshot(snap *node.VolumeSnapshot) error {
+	backend, err := s.snapshotBackend(snap)
+	if err != nil {
+		return err
+	}
+
+	err = backend.DeleteSnapshot(snap.GetVolume(), snap.GetName())
+	if err != nil {
+		return fmt.Errorf("Failed to delete snapshot %s of volume %s: %s",
+			snap.GetName(), snap.GetVolume().GetVolumeID(), err)
+	}
+
+	return nil
+}
+
+// cloneVolume creates a volume from a snapshot, the clone uses the backend
+// of the snapshot's volume
+func (s *storage) cloneVolume(req *node.CloneRequest) error {
+	snap := req.GetSnapshot()
+	backend, err := s.snapshotBackend(snap)
+	if err != nil {
+		return err
+	}
+
+	target := req.GetTarget()
+	if target.GetVolumeID() == "" {
+		return fmt.Errorf("No volume ID given for the clone")
+	}
+	err = checkName("volume ID", target.GetVolumeID())
+	if err != nil {
+		return err
+	}
+
+	sourceBackend := snap.GetVolume().GetBackend()
+	if sourceBackend == "" {
+		sourceBackend = s.defaultBackend
+	}
+	if target.GetBackend() != "" && target.GetBackend() != sourceBackend {
+		return fmt.Errorf("Can't clone a %s volume to the %s backend",
+			sourceBackend, target.GetBackend())
+	}
+
+	err = backend.Clone(snap.GetVolume(), snap.GetName(), target)
+	if err != nil {
+		return fmt.Errorf("Failed to clone snapshot %s of volume %s to %s: %s",
+			snap.GetName(), snap.GetVolume().GetVolumeID(), target.GetVolumeID(), err)
+	}
+
+	return nil
+}
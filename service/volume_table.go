@@ -0,0 +1,224 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	node "github.com/PUMATeam/catapult-node/pb"
+)
+
+const defaultVolumeTable = "/var/lib/catapult-node/volumes.json"
+
+// volumeMapping records where a connected volume is mapped on the node
+type volumeMapping struct {
+	VolumeID string `json:"volumeID"`
+	PoolName string `json:"poolName"`
+	Backend  string `json:"backend"`
+	Device   string `json:"device"`
+	// Owner is the VM that booted from the volume, DeleteVM disconnects it
+	Owner string `json:"owner,omitempty"`
+	// Erase is how the volume's data is erased whenever it is disconnected
+	Erase node.EraseMode `json:"erase,omitempty"`
+}
+
+func (m *volumeMapping) volume() *node.Volume {
+	return &node.Volume{
+		VolumeID: m.VolumeID,
+		PoolName: m.PoolName,
+		Backend:  m.Backend,
+	}
+}
+
+// volumeTable keeps track of the connected volumes, it is persisted so
+// the mappings survive restarts of the node
+type volumeTable struct {
+	sync.Mutex
+	path    string
+	volumes map[string]*volumeMapping
+	// reserved holds what is being done to volumes being connected,
+	// disconnected or started from, they are not persisted
+	reserved map[string]string
+}
+
+func loadVolumeTable(path string) (*volumeTable, error) {
+	t := &volumeTable{
+		path:     path,
+		volumes:  make(map[string]*volumeMapping),
+		reserved: make(map[string]string),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+
+	// a table that can't be read in full is not used at all
+	var volumes map[string]*volumeMapping
+	err = json.Unmarshal(b, &volumes)
+	if err != nil {
+		return t, fmt.Errorf("Invalid volume table %s: %s", path, err)
+	}
+
+	if volumes != nil {
+		t.volumes = volumes
+	}
+
+	return t, nil
+}
+
+// save writes the table to a temporary file first so a crash never
+// leaves a truncated table behind, callers must hold the lock
+func (t *volumeTable) save() error {
+	b, err := json.MarshalIndent(t.volumes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(t.path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := t.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, t.path)
+}
+
+func (t *volumeTable) get(volumeID string) *volumeMapping {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.volumes[volumeID]
+}
+
+func (t *volumeTable) add(m *volumeMapping) error {
+	t.Lock()
+	defer t.Unlock()
+
+	t.volumes[m.VolumeID] = m
+	return t.save()
+}
+
+// reserve marks a volume as being connected, so concurrent connections of
+// the same volume fail instead of attaching it twice
+func (t *volumeTable) reserve(volumeID string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if m := t.volumes[volumeID]; m != nil {
+		return fmt.Errorf("Volume %s is already connected at %s", volumeID, m.Device)
+	}
+
+	if action, ok := t.reserved[volumeID]; ok {
+		return fmt.Errorf("Volume %s is already being %s", volumeID, action)
+	}
+
+	t.reserved[volumeID] = "connected"
+	return nil
+}
+
+// reserveConnected marks a connected volume as being disconnected, so it
+// is neither disconnected twice nor started from in the meantime
+func (t *volumeTable) reserveConnected(volumeID string) (*volumeMapping, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	m := t.volumes[volumeID]
+	if m == nil {
+		return nil, fmt.Errorf("Volume %s is not connected", volumeID)
+	}
+
+	if action, ok := t.reserved[volumeID]; ok {
+		return nil, fmt.Errorf("Volume %s is already being %s", volumeID, action)
+	}
+
+	t.reserved[volumeID] = "disconnected"
+	return m, nil
+}
+
+// unreserve ends the reservation taken by reserve, reserveConnected or
+// claim
+func (t *volumeTable) unreserve(volumeID string) {
+	t.Lock()
+	delete(t.reserved, volumeID)
+	t.Unlock()
+}
+
+func (t *volumeTable) remove(volumeID string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.volumes, volumeID)
+	return t.save()
+}
+
+// byDevice returns the mapping of the volume connected at device
+func (t *volumeTable) byDevice(device string) *volumeMapping {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, m := range t.volumes {
+		if m.Device == device {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// claim reserves the volume connected at device, if any, for the VM
+// being started from it and records the VM as its owner. Volumes being
+// connected or disconnected can't be claimed. It returns the ID of the
+// claimed volume, which is unreserved once the VM is started
+func (t *volumeTable) claim(device, vmID string) (string, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, m := range t.volumes {
+		if m.Device != device {
+			continue
+		}
+
+		if action, ok := t.reserved[m.VolumeID]; ok {
+			return "", fmt.Errorf("Volume %s is being %s", m.VolumeID, action)
+		}
+
+		t.reserved[m.VolumeID] = "started from"
+		m.Owner = vmID
+		err := t.save()
+		if err != nil {
+			delete(t.reserved, m.VolumeID)
+			return "", err
+		}
+
+		return m.VolumeID, nil
+	}
+
+	return "", nil
+}
+
+// ownedBy returns the volumes the VM booted from
+func (t *volumeTable) ownedBy(vmID string) []*volumeMapping {
+	t.Lock()
+	defer t.Unlock()
+
+	var owned []*volumeMapping
+	for _, m := range t.volumes {
+		if m.Owner == vmID {
+			owned = append(owned, m)
+		}
+	}
+
+	return owned
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVolumeTablePersists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "volumes.json")
+	table, err := loadVolumeTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &volumeMapping{
+		VolumeID: "1",
+		PoolName: "rbd",
+		Backend:  backendRBD,
+		Device:   "/dev/nbd0",
+	}
+	if err = table.add(expected); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.add(&volumeMapping{VolumeID: "2", Device: "/dev/nbd1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.remove("2"); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err = loadVolumeTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := table.byDevice("/dev/nbd0"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("\n\tGOT: %v \n\tEXPECTED: %v", got, expected)
+	}
+
+	if got := table.get("2"); got != nil {
+		t.Errorf("removed volume is still mapped to %s", got.Device)
+	}
+}
+
+func TestVolumeTableReserve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := loadVolumeTable(filepath.Join(dir, "volumes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = table.reserve("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.reserve("1"); err == nil {
+		t.Error("a volume being connected was reserved twice")
+	}
+
+	if err = table.add(&volumeMapping{VolumeID: "1", Device: "/dev/nbd0"}); err != nil {
+		t.Fatal(err)
+	}
+	table.unreserve("1")
+	if err = table.reserve("1"); err == nil {
+		t.Error("a connected volume was reserved")
+	}
+
+	if err = table.reserve("2"); err != nil {
+		t.Fatal(err)
+	}
+	table.unreserve("2")
+	if err = table.reserve("2"); err != nil {
+		t.Errorf("a released reservation is still held: %s", err)
+	}
+}
+
+func TestLoadInvalidVolumeTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "volumes.json")
+	for content, valid := range map[string]bool{
+		"null":                   true,
+		`{"1": {"volumeID": "1"`: false,
+	} {
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		table, err := loadVolumeTable(path)
+		if valid != (err == nil) {
+			t.Errorf("loading %q returned %v", content, err)
+		}
+		if len(table.volumes) != 0 {
+			t.Errorf("loading %q left volumes %v", content, table.volumes)
+		}
+
+		if err = table.add(&volumeMapping{VolumeID: "2", Device: "/dev/nbd0"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVolumeTableReserveConnected(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volumes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := loadVolumeTable(filepath.Join(dir, "volumes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = table.reserveConnected("1"); err == nil {
+		t.Error("a volume that is not connected was reserved")
+	}
+
+	if err = table.add(&volumeMapping{VolumeID: "1", Device: "/dev/nbd0"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = table.reserveConnected("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = table.reserveConnected("1"); err == nil {
+		t.Error("a volume being disconnected was reserved twice")
+	}
+	if _, err = table.claim("/dev/nbd0", "vm"); err == nil {
+		t.Error("a volume being disconnected was started from")
+	}
+
+	table.unreserve("1")
+	claimed, err := table.claim("/dev/nbd0", "vm")
+	if err != nil || claimed != "1" {
+		t.Fatalf("claiming the volume returned %q, %v", claimed, err)
+	}
+	if _, err = table.reserveConnected("1"); err == nil {
+		t.Error("a volume being started from was reserved")
+	}
+	table.unreserve(claimed)
+
+	if owned := table.ownedBy("vm"); len(owned) != 1 || owned[0].VolumeID != "1" {
+		t.Errorf("unexpected volumes owned by the VM: %v", owned)
+	}
+	if claimed, err = table.claim("/dev/nbd1", "vm"); err != nil || claimed != "" {
+		t.Errorf("claiming a device that is no volume returned %q, %v", claimed, err)
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 
@@ -18,7 +19,13 @@ func StringToUUID(str string) uuid.UUID {
 }
 
 func ExecuteCommand(command string, args ...string) (string, error) {
-	cmd := exec.Command(command, args...)
+	return ExecuteCommandContext(context.Background(), command, args...)
+}
+
+// ExecuteCommandContext is ExecuteCommand killing the command once ctx is
+// done
+func ExecuteCommandContext(ctx context.Context, command string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("command %q exited with %q: %v", cmd.Args, out, err)